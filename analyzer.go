@@ -0,0 +1,231 @@
+package gomoddirectives
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// NewAnalyzer creates an analyzer that reports the problems of the go.mod files.
+// The flags of the analyzer are bound to the options.
+// The go.mod file of a module is reported only once, even if the module contains several packages.
+func NewAnalyzer(opts Options) *analysis.Analyzer {
+	r := &runner{opts: opts, seen: map[string]bool{}}
+
+	a := &analysis.Analyzer{
+		Name: "gomoddirectives",
+		Doc:  "A linter that handle directives into `go.mod`.",
+		URL:  "https://github.com/ldez/gomoddirectives",
+		Run:  r.run,
+	}
+
+	bindFlags(&a.Flags, &r.opts)
+
+	return a
+}
+
+// bindFlags binds the flags to the options.
+func bindFlags(fs *flag.FlagSet, opts *Options) {
+	fs.BoolVar(&opts.ReplaceAllowAll, "replace-allow-all", opts.ReplaceAllowAll, "Allow all replace directives")
+	fs.Var((*stringsFlag)(&opts.ReplaceAllowList), "replace-allow-list", "List of allowed replace directives (comma separated)")
+	fs.BoolVar(&opts.ReplaceAllowLocal, "replace-local", opts.ReplaceAllowLocal, "Allow local replace directives")
+	fs.BoolVar(&opts.ExcludeForbidden, "exclude-forbidden", opts.ExcludeForbidden, "Forbid the use of exclude directives")
+	fs.Var((*stringsFlag)(&opts.ExcludeAllowList), "exclude-allow-list", "List of modules allowed to be excluded (comma separated)")
+	fs.BoolVar(&opts.ExcludeCheckRequire, "exclude-check-require", opts.ExcludeCheckRequire, "Check that excluded modules are required, but not at the excluded version, and detect duplicated exclusions")
+	fs.BoolVar(&opts.IgnoreForbidden, "ignore-forbidden", opts.IgnoreForbidden, "Forbid the use of ignore directives")
+	fs.Var((*stringsFlag)(&opts.IgnoreAllowList), "ignore-allow-list", "List of allowed ignore directives (patterns, comma separated)")
+	fs.BoolVar(&opts.IgnoreCheckPaths, "ignore-check-paths", opts.IgnoreCheckPaths, "Check that ignored paths exist and don't contain Go packages of the module")
+	fs.BoolVar(&opts.RetractAllowNoExplanation, "retract-allow-no-explanation", opts.RetractAllowNoExplanation, "Allow to use retract directives without explanation")
+	fs.Var(&regexpFlag{re: &opts.RetractRationalePattern}, "retract-rationale-pattern", "Pattern to validate the explanation of retract directives")
+	fs.BoolVar(&opts.RetractCheckRelease, "retract-check-release", opts.RetractCheckRelease, "Check that the current release (latest local git tag) is not retracted")
+	fs.BoolVar(&opts.RetractCheckRanges, "retract-check-ranges", opts.RetractCheckRanges, "Check that retract ranges are well-formed, and detect duplicated or overlapping retract directives")
+	fs.BoolVar(&opts.RetractCheckTags, "retract-check-tags", opts.RetractCheckTags, "Check that retracted versions match tags of the local git repository")
+	fs.BoolVar(&opts.ToolchainForbidden, "toolchain-forbidden", opts.ToolchainForbidden, "Forbid the use of toolchain directive")
+	fs.Var(&regexpFlag{re: &opts.ToolchainPattern}, "toolchain-pattern", "Pattern to validate toolchain directive")
+	fs.BoolVar(&opts.ToolForbidden, "tool-forbidden", opts.ToolForbidden, "Forbid the use of tool directives")
+	fs.Var((*stringsFlag)(&opts.ToolAllowList), "tool-allow-list", "List of allowed tool directives (patterns, comma separated)")
+	fs.BoolVar(&opts.ToolCheckRequire, "tool-check-require", opts.ToolCheckRequire, "Check that tools are provided by a required module or the main module, and detect duplicated tools")
+	fs.BoolVar(&opts.GoDebugForbidden, "go-debug-forbidden", opts.GoDebugForbidden, "Forbid the use of godebug directives")
+	fs.Var(&regexpFlag{re: &opts.GoVersionPattern}, "go-version-pattern", "Pattern to validate go min version directive")
+	fs.BoolVar(&opts.CheckModulePath, "check-module-path", opts.CheckModulePath, "Check module path validity")
+	fs.Var((*stringsFlag)(&opts.ModulePathPrefixes), "module-path-prefixes", "List of allowed module path prefixes (comma separated)")
+	fs.BoolVar(&opts.ModulePathCheckVCS, "module-path-check-vcs", opts.ModulePathCheckVCS, "Check that the module path matches the origin remote of the local git repository")
+	fs.BoolVar(&opts.ModulePathCheckMajor, "module-path-check-major", opts.ModulePathCheckMajor, "Check that the major version suffix of the module path matches the latest local git tag")
+	fs.BoolVar(&opts.DeprecatedForbidden, "deprecated-forbidden", opts.DeprecatedForbidden, "Forbid the deprecation of the module")
+	fs.Var(&regexpFlag{re: &opts.DeprecatedPattern}, "deprecated-pattern", "Pattern to validate the deprecation message of the module")
+	fs.BoolVar(&opts.RequireCheckDeprecated, "require-check-deprecated", opts.RequireCheckDeprecated, "Detect deprecated dependencies (from the local module cache)")
+	fs.BoolVar(&opts.RequireCheckRetracted, "require-check-retracted", opts.RequireCheckRetracted, "Detect required versions retracted by their module (from the local module cache)")
+	fs.BoolVar(&opts.RequireCheckGoVersion, "require-check-go-version", opts.RequireCheckGoVersion, "Check that the go (and toolchain) directive is not lower than the ones of the dependencies")
+	fs.BoolVar(&opts.RequireCheckBlocks, "require-check-blocks", opts.RequireCheckBlocks, "Check that direct and indirect requirements are in separate blocks")
+	fs.BoolVar(&opts.CheckGoSum, "check-go-sum", opts.CheckGoSum, "Check the consistency of the go.sum file")
+	fs.BoolVar(&opts.CheckVendor, "check-vendor", opts.CheckVendor, "Check the consistency of the vendor/modules.txt file")
+	fs.BoolVar(&opts.CheckFormat, "check-format", opts.CheckFormat, "Check that the go.mod file is formatted canonically")
+	fs.BoolVar(&opts.CheckLayout, "check-layout", opts.CheckLayout, "Check the order of the directives")
+	fs.Var((*stringsFlag)(&opts.LayoutOrder), "layout-order", "Order of the directives (comma separated)")
+	fs.BoolVar(&opts.PureGoDiscovery, "pure-go-discovery", opts.PureGoDiscovery, "Find the go.mod file without calling the go command")
+}
+
+type runner struct {
+	opts Options
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func (r *runner) run(pass *analysis.Pass) (any, error) {
+	goMod, err := passGoMod(context.Background(), pass, r.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.markSeen(goMod) {
+		return nil, nil //nolint:nilnil // the analyzer has no result.
+	}
+
+	file, err := parseGoMod(goMod)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", goMod, err)
+	}
+
+	reportResults(pass, AnalyzeFile(file, r.opts))
+
+	return nil, nil //nolint:nilnil // the analyzer has no result.
+}
+
+// markSeen marks a go.mod file as analyzed.
+// Returns false if the file has already been analyzed.
+func (r *runner) markSeen(goMod string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.seen[goMod] {
+		return false
+	}
+
+	r.seen[goMod] = true
+
+	return true
+}
+
+// reportResults reports the results as diagnostics.
+// The files of the results are registered in the file set of the pass.
+// The informative results are not reported: a diagnostic is a failure for the drivers (multichecker, golangci-lint).
+func reportResults(pass *analysis.Pass, results []Result) {
+	files := map[string]*token.File{}
+
+	for _, result := range results {
+		if result.Severity == SeverityInfo {
+			continue
+		}
+
+		filename := result.Start.Filename
+
+		tf, ok := files[filename]
+		if !ok {
+			tf = addFile(pass.Fset, filename)
+			files[filename] = tf
+		}
+
+		if tf == nil {
+			continue
+		}
+
+		diagnostic := analysis.Diagnostic{
+			Pos:      positionToPos(tf, result.Start),
+			End:      positionToPos(tf, result.End),
+			Category: result.Severity.String(),
+			Message:  result.Reason,
+		}
+
+		if len(result.Edits) > 0 {
+			fix := analysis.SuggestedFix{Message: "Fix " + strings.SplitN(result.Reason, "\n", 2)[0]}
+
+			for _, edit := range result.Edits {
+				fix.TextEdits = append(fix.TextEdits, analysis.TextEdit{
+					Pos:     tf.Pos(min(edit.Start, tf.Size())),
+					End:     tf.Pos(min(edit.End, tf.Size())),
+					NewText: edit.NewText,
+				})
+			}
+
+			diagnostic.SuggestedFixes = []analysis.SuggestedFix{fix}
+		}
+
+		pass.Report(diagnostic)
+	}
+}
+
+// addFile registers a file in the file set.
+// Returns nil if the file cannot be read.
+func addFile(fset *token.FileSet, filename string) *token.File {
+	raw, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil
+	}
+
+	tf := fset.AddFile(filename, -1, len(raw))
+	tf.SetLinesForContent(raw)
+
+	return tf
+}
+
+// positionToPos converts a line and a column to a position inside a file.
+func positionToPos(tf *token.File, position token.Position) token.Pos {
+	if position.Line < 1 || position.Line > tf.LineCount() {
+		return tf.Pos(0)
+	}
+
+	start := tf.LineStart(position.Line)
+
+	offset := tf.Offset(start) + max(position.Column-1, 0)
+
+	return tf.Pos(min(offset, tf.Size()))
+}
+
+// stringsFlag a flag.Value for a comma separated list of strings.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, strings.Split(s, ",")...)
+	return nil
+}
+
+// regexpFlag a flag.Value for a regular expression.
+type regexpFlag struct {
+	re **regexp.Regexp
+}
+
+func (f *regexpFlag) String() string {
+	if f.re == nil || *f.re == nil {
+		return ""
+	}
+
+	return (*f.re).String()
+}
+
+func (f *regexpFlag) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+
+	*f.re = re
+
+	return nil
+}
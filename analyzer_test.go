@@ -0,0 +1,107 @@
+package gomoddirectives
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestNewAnalyzer(t *testing.T) {
+	a := NewAnalyzer(Options{})
+
+	require.NoError(t, a.Flags.Set("go-version-pattern", `^1\.10$`))
+	require.NoError(t, a.Flags.Set("replace-allow-list", "example.com/a,example.com/b"))
+
+	var diagnostics []analysis.Diagnostic
+
+	pass := &analysis.Pass{
+		Analyzer: a,
+		Fset:     token.NewFileSet(),
+		Report: func(d analysis.Diagnostic) {
+			diagnostics = append(diagnostics, d)
+		},
+	}
+
+	// the go.mod file is reported only once.
+	for range 2 {
+		_, err := a.Run(pass)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, diagnostics, 1)
+
+	assert.Contains(t, diagnostics[0].Message, `doesn't match the pattern '^1\.10$'`)
+	assert.Equal(t, "error", diagnostics[0].Category)
+
+	position := pass.Fset.Position(diagnostics[0].Pos)
+
+	goMod, err := filepath.Abs("go.mod")
+	require.NoError(t, err)
+
+	assert.Equal(t, goMod, position.Filename)
+	assert.Equal(t, 3, position.Line)
+	assert.Equal(t, 1, position.Column)
+}
+
+func TestReportResults(t *testing.T) {
+	file := writeGoMod(t, t.TempDir(), unformattedGoMod)
+
+	results := AnalyzeFile(file, Options{CheckFormat: true})
+	require.NotEmpty(t, results)
+
+	var diagnostics []analysis.Diagnostic
+
+	pass := &analysis.Pass{
+		Fset: token.NewFileSet(),
+		Report: func(d analysis.Diagnostic) {
+			diagnostics = append(diagnostics, d)
+		},
+	}
+
+	reportResults(pass, results)
+
+	require.Len(t, diagnostics, len(results))
+
+	for i, diagnostic := range diagnostics {
+		start := pass.Fset.Position(diagnostic.Pos)
+
+		assert.Equal(t, results[i].Start.Line, start.Line)
+		assert.Equal(t, results[i].Start.Column, start.Column)
+
+		require.Len(t, diagnostic.SuggestedFixes, 1)
+		require.Len(t, diagnostic.SuggestedFixes[0].TextEdits, 1)
+
+		edit := diagnostic.SuggestedFixes[0].TextEdits[0]
+
+		assert.Equal(t, results[i].Edits[0].Start, pass.Fset.Position(edit.Pos).Offset)
+		assert.Equal(t, results[i].Edits[0].End, pass.Fset.Position(edit.End).Offset)
+	}
+}
+
+func TestReportResults_info(t *testing.T) {
+	file := writeGoMod(t, t.TempDir(), formattedGoMod)
+
+	results := []Result{
+		NewResult(file, file.Module.Syntax, "error"),
+		{Reason: "info", Start: token.Position{Filename: file.Syntax.Name, Line: 1, Column: 1}, Severity: SeverityInfo},
+	}
+
+	var diagnostics []analysis.Diagnostic
+
+	pass := &analysis.Pass{
+		Fset: token.NewFileSet(),
+		Report: func(d analysis.Diagnostic) {
+			diagnostics = append(diagnostics, d)
+		},
+	}
+
+	reportResults(pass, results)
+
+	require.Len(t, diagnostics, 1)
+
+	assert.Equal(t, "error", diagnostics[0].Message)
+}
@@ -0,0 +1,108 @@
+package gomoddirectives
+
+import (
+	"fmt"
+	"go/version"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// AnalyzeChanges analyzes the changes between 2 versions of a mod file:
+//   - a dependency must not be downgraded.
+//   - the go directive must not be lowered.
+//   - a new replace directive must be allowed by [Options.ReplaceAllowList].
+//   - a major upgrade of a dependency must be reviewed (warning).
+//
+// The results are positioned in the new version of the mod file.
+// A nil base (ex: a new module) is an empty mod file: all the replace directives are new.
+func AnalyzeChanges(base, head *modfile.File, opts Options) []Result {
+	if base == nil {
+		base = &modfile.File{}
+	}
+
+	var results []Result
+
+	results = append(results, checkChangeGoVersion(base, head)...)
+	results = append(results, checkChangeRequire(base, head)...)
+	results = append(results, checkChangeReplace(base, head, opts)...)
+
+	return results
+}
+
+func checkChangeGoVersion(base, head *modfile.File) []Result {
+	if base.Go == nil || head.Go == nil {
+		return nil
+	}
+
+	if version.Compare("go"+head.Go.Version, "go"+base.Go.Version) >= 0 {
+		return nil
+	}
+
+	return []Result{NewResult(head, head.Go.Syntax, fmt.Sprintf(reasonChangeGoVersion, base.Go.Version, head.Go.Version))}
+}
+
+func checkChangeRequire(base, head *modfile.File) []Result {
+	var results []Result
+
+	// the highest required version of each module path, and of each module path without major version suffix.
+	versions := map[string]string{}
+	majors := map[string]module.Version{}
+
+	for _, req := range base.Require {
+		if v, ok := versions[req.Mod.Path]; !ok || semver.Compare(req.Mod.Version, v) > 0 {
+			versions[req.Mod.Path] = req.Mod.Version
+		}
+
+		prefix, _, _ := module.SplitPathVersion(req.Mod.Path)
+		if m, ok := majors[prefix]; !ok || compareMajor(req.Mod, m) > 0 {
+			majors[prefix] = req.Mod
+		}
+	}
+
+	for _, req := range head.Require {
+		if v, ok := versions[req.Mod.Path]; ok && semver.Compare(req.Mod.Version, v) < 0 {
+			results = append(results, NewResult(head, req.Syntax, fmt.Sprintf(reasonChangeDowngrade, req.Mod.Path, v, req.Mod.Version)))
+			continue
+		}
+
+		prefix, _, _ := module.SplitPathVersion(req.Mod.Path)
+
+		previous, ok := majors[prefix]
+		if !ok || compareMajor(req.Mod, previous) <= 0 {
+			continue
+		}
+
+		result := NewResult(head, req.Syntax, fmt.Sprintf(reasonChangeMajor, prefix, previous, req.Mod))
+		result.Severity = SeverityWarning
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// compareMajor compares the major versions of 2 module versions.
+func compareMajor(a, b module.Version) int {
+	return semver.Compare(semver.Major(a.Version), semver.Major(b.Version))
+}
+
+func checkChangeReplace(base, head *modfile.File, opts Options) []Result {
+	var results []Result
+
+	for _, replace := range head.Replace {
+		known := slices.ContainsFunc(base.Replace, func(r *modfile.Replace) bool {
+			return r.Old == replace.Old
+		})
+
+		if known || slices.Contains(opts.ReplaceAllowList, replace.Old.Path) {
+			continue
+		}
+
+		results = append(results, NewResult(head, replace.Syntax, fmt.Sprintf(reasonChangeReplace, replace.Old.Path)))
+	}
+
+	return results
+}
@@ -0,0 +1,112 @@
+package gomoddirectives
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+
+func TestAnalyzeChanges(t *testing.T) {
+	base, err := modfile.Parse("base.mod", []byte(`module example.com/foo
+
+go 1.23
+
+require (
+	github.com/a/a v1.2.0
+	github.com/b/b v1.5.0
+	github.com/c/c v2.0.0+incompatible
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+replace github.com/a/a => github.com/fork/a v1.2.1
+`), nil)
+	require.NoError(t, err)
+
+	head, err := modfile.Parse("go.mod", []byte(`module example.com/foo
+
+go 1.22
+
+require (
+	github.com/a/a v1.1.0
+	github.com/b/b/v2 v2.0.0
+	github.com/c/c v3.0.0+incompatible
+	gopkg.in/yaml.v2 v2.4.0
+	github.com/d/d v1.0.0
+)
+
+replace (
+	github.com/a/a => github.com/fork/a v1.2.2
+	github.com/d/d => github.com/fork/d v1.0.1
+	github.com/e/e => ../e
+)
+`), nil)
+	require.NoError(t, err)
+
+	results := AnalyzeChanges(base, head, Options{ReplaceAllowList: []string{"github.com/e/e"}})
+
+	expected := []Result{
+		{
+			Reason: "go directive is lowered from 1.23 to 1.22",
+			Start:  token.Position{Filename: "go.mod", Line: 3, Column: 1},
+			End:    token.Position{Filename: "go.mod", Line: 3, Column: 8},
+		},
+		{
+			Reason: "the dependency github.com/a/a is downgraded from v1.2.0 to v1.1.0",
+			Start:  token.Position{Filename: "go.mod", Line: 6, Column: 2},
+			End:    token.Position{Filename: "go.mod", Line: 6, Column: 23},
+		},
+		{
+			Reason:   "major upgrade of github.com/b/b (github.com/b/b@v1.5.0 => github.com/b/b/v2@v2.0.0) must be reviewed",
+			Start:    token.Position{Filename: "go.mod", Line: 7, Column: 2},
+			End:      token.Position{Filename: "go.mod", Line: 7, Column: 26},
+			Severity: SeverityWarning,
+		},
+		{
+			Reason:   "major upgrade of github.com/c/c (github.com/c/c@v2.0.0+incompatible => github.com/c/c@v3.0.0+incompatible) must be reviewed",
+			Start:    token.Position{Filename: "go.mod", Line: 8, Column: 2},
+			End:      token.Position{Filename: "go.mod", Line: 8, Column: 36},
+			Severity: SeverityWarning,
+		},
+		{
+			Reason: "new replace directive not in the allow list: github.com/d/d",
+			Start:  token.Position{Filename: "go.mod", Line: 15, Column: 2},
+			End:    token.Position{Filename: "go.mod", Line: 15, Column: 44},
+		},
+	}
+
+	assert.Equal(t, expected, results)
+}
+
+func TestAnalyzeChanges_identical(t *testing.T) {
+	file, err := modfile.Parse("go.mod", []byte(headDiffGoMod), nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, AnalyzeChanges(file, file, Options{}))
+}
+
+func TestAnalyzeChanges_newModule(t *testing.T) {
+	head, err := modfile.Parse("go.mod", []byte(`module example.com/foo
+
+go 1.22
+
+require github.com/a/a v1.1.0
+
+replace github.com/a/a => github.com/fork/a v1.2.2
+`), nil)
+	require.NoError(t, err)
+
+	results := AnalyzeChanges(nil, head, Options{})
+
+	expected := []Result{
+		{
+			Reason: "new replace directive not in the allow list: github.com/a/a",
+			Start:  token.Position{Filename: "go.mod", Line: 7, Column: 1},
+			End:    token.Position{Filename: "go.mod", Line: 7, Column: 51},
+		},
+	}
+
+	assert.Equal(t, expected, results)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ldez/gomoddirectives"
+)
+
+// applyFixes applies the edits of the results to the files.
+// The edits of a result that overlap the edits of a previous result are not applied.
+// Returns the results that have not been fixed.
+func applyFixes(results []gomoddirectives.Result) ([]gomoddirectives.Result, error) {
+	var (
+		remaining []gomoddirectives.Result
+		filenames []string
+	)
+
+	edits := map[string][]gomoddirectives.TextEdit{}
+
+	for _, result := range results {
+		filename := result.Start.Filename
+
+		if len(result.Edits) == 0 || overlaps(edits[filename], result.Edits) {
+			remaining = append(remaining, result)
+			continue
+		}
+
+		if _, ok := edits[filename]; !ok {
+			filenames = append(filenames, filename)
+		}
+
+		edits[filename] = append(edits[filename], result.Edits...)
+	}
+
+	for _, filename := range filenames {
+		err := fixFile(filename, edits[filename])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return remaining, nil
+}
+
+// overlaps checks if an edit overlaps one of the accepted edits.
+// The identical edits don't overlap.
+func overlaps(accepted, edits []gomoddirectives.TextEdit) bool {
+	for _, edit := range edits {
+		conflict := slices.ContainsFunc(accepted, func(a gomoddirectives.TextEdit) bool {
+			if a.Start == edit.Start && a.End == edit.End && string(a.NewText) == string(edit.NewText) {
+				return false
+			}
+
+			return a.Start < edit.End && edit.Start < a.End || a.Start == edit.Start
+		})
+
+		if conflict {
+			return true
+		}
+	}
+
+	return false
+}
+
+func fixFile(filename string, edits []gomoddirectives.TextEdit) error {
+	filename = filepath.Clean(filename)
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	fixed, err := gomoddirectives.ApplyEdits(raw, edits)
+	if err != nil {
+		return fmt.Errorf("fix %s: %w", filename, err)
+	}
+
+	return os.WriteFile(filename, fixed, info.Mode().Perm())
+}
@@ -1,118 +1,316 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
-	"regexp"
+	"path/filepath"
 	"strings"
 
 	"github.com/ldez/gomoddirectives"
+	"golang.org/x/mod/modfile"
 )
 
-//nolint:recvcheck // required for the marshaling.
-type flagSlice []string
+// listFlag a flag for a list of values (comma separated, or multiple flags).
+// The values of the flag replace the values of the configuration file.
+type listFlag struct {
+	values *[]string
+	set    bool
+}
+
+func newListFlag(values *[]string) *listFlag {
+	return &listFlag{values: values}
+}
 
-func (f flagSlice) String() string {
-	return strings.Join(f, ":")
+func (f *listFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+
+	return strings.Join(*f.values, ":")
 }
 
-func (f *flagSlice) Set(s string) error {
-	*f = append(*f, strings.Split(s, ",")...)
+func (f *listFlag) Set(s string) error {
+	if !f.set {
+		*f.values = nil
+		f.set = true
+	}
+
+	*f.values = append(*f.values, strings.Split(s, ",")...)
+
 	return nil
 }
 
 type config struct {
-	ReplaceAllowAll           bool
-	ReplaceAllowList          flagSlice
-	ReplaceAllowLocal         bool
-	ExcludeForbidden          bool
-	IgnoreForbidden           bool
-	RetractAllowNoExplanation bool
-	ToolchainForbidden        bool
-	ToolForbidden             bool
-	GoDebugForbidden          bool
-	GoVersionPattern          string
-	ToolchainPattern          string
-	CheckModulePath           bool
+	gomoddirectives.Config
+
+	ConfigFile    string
+	Fix           bool
+	NewFromRev    string
+	Base          string
+	StdinFilename string
+	Help          bool
 }
 
 func main() {
-	cfg := config{}
+	// the preset and the configuration file are loaded before the definition of the flags:
+	// the values of the configuration file are the default values of the flags.
+	preCfg := preParseFlags(os.Args[1:])
+
+	configFile := preCfg.ConfigFile
+	if configFile == "" {
+		configFile = findConfigFile()
+	}
 
-	flag.BoolVar(&cfg.ExcludeForbidden, "exclude", false, "Forbid the use of exclude directives")
-	flag.BoolVar(&cfg.IgnoreForbidden, "ignore", false, "Forbid the use of ignore directives")
-	flag.BoolVar(&cfg.ReplaceAllowAll, "all-replace", false, "Allow all replace directives")
-	flag.Var(&cfg.ReplaceAllowList, "list", "List of allowed replace directives")
-	flag.BoolVar(&cfg.ReplaceAllowLocal, "local", false, "Allow local replace directives")
-	flag.BoolVar(&cfg.RetractAllowNoExplanation, "retract-no-explanation", false, "Allow to use retract directives without explanation")
-	flag.BoolVar(&cfg.ToolchainForbidden, "toolchain", false, "Forbid the use of toolchain directive")
-	flag.StringVar(&cfg.ToolchainPattern, "toolchain-pattern", "", "Pattern to validate toolchain directive")
-	flag.BoolVar(&cfg.ToolForbidden, "tool", false, "Forbid the use of tool directives")
-	flag.BoolVar(&cfg.GoDebugForbidden, "godebug", false, "Forbid the use of godebug directives")
-	flag.StringVar(&cfg.GoVersionPattern, "goversion", "", "Pattern to validate go min version directive")
-	flag.BoolVar(&cfg.CheckModulePath, "check-module-path", false, "Check module path validity")
-
-	help := flag.Bool("h", false, "Show this help.")
+	fileCfg, err := loadConfig(configFile, preCfg.Preset)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the overrides of the configuration are relative to the directory of the configuration file.
+	root := filepath.Dir(cmp.Or(configFile, gomoddirectives.ConfigFileName))
+
+	cfg := config{Config: *fileCfg}
+
+	bindFlags(flag.CommandLine, &cfg)
 
 	flag.Usage = usage
 
 	flag.Parse()
 
-	if *help {
+	if cfg.Help {
 		usage()
 	}
 
-	opts := gomoddirectives.Options{
-		ReplaceAllowAll:           cfg.ReplaceAllowAll,
-		ReplaceAllowList:          cfg.ReplaceAllowList,
-		ReplaceAllowLocal:         cfg.ReplaceAllowLocal,
-		ExcludeForbidden:          cfg.ExcludeForbidden,
-		IgnoreForbidden:           cfg.IgnoreForbidden,
-		RetractAllowNoExplanation: cfg.RetractAllowNoExplanation,
-		ToolchainForbidden:        cfg.ToolchainForbidden,
-		ToolForbidden:             cfg.ToolForbidden,
-		GoDebugForbidden:          cfg.GoDebugForbidden,
-		CheckModulePath:           cfg.CheckModulePath,
+	ctx := context.Background()
+
+	inputs, err := readInputs(ctx, cfg, flag.Args())
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	if cfg.GoVersionPattern != "" {
-		var err error
+	var results []gomoddirectives.Result
 
-		opts.GoVersionPattern, err = regexp.Compile(cfg.GoVersionPattern)
+	for _, in := range inputs {
+		if cfg.Fix && in.Content != nil {
+			log.Fatal("-fix cannot be used with the standard input")
+		}
+
+		opts, err := moduleOptions(fileCfg, root, in, os.Args[1:])
 		if err != nil {
 			log.Fatal(err)
 		}
-	}
 
-	if cfg.ToolchainPattern != "" {
-		var err error
-
-		opts.ToolchainPattern, err = regexp.Compile(cfg.ToolchainPattern)
+		r, err := analyze(ctx, cfg, in, opts)
 		if err != nil {
 			log.Fatal(err)
 		}
+
+		results = append(results, r...)
 	}
 
-	results, err := gomoddirectives.Analyze(opts)
-	if err != nil {
-		log.Fatal(err)
+	if cfg.Fix {
+		results, err = applyFixes(results)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
+	var failed bool
+
 	for _, e := range results {
 		fmt.Println(e)
+
+		failed = failed || e.Severity != gomoddirectives.SeverityInfo
 	}
 
-	if len(results) > 0 {
+	if failed {
 		os.Exit(1)
 	}
 }
 
+func analyze(ctx context.Context, cfg config, in input, opts gomoddirectives.Options) ([]gomoddirectives.Result, error) {
+	file := in.File
+
+	if in.Content != nil {
+		opts = opts.WithContent(in.Content)
+	}
+
+	var results []gomoddirectives.Result
+
+	if cfg.NewFromRev == "" {
+		results = gomoddirectives.AnalyzeFile(file, opts)
+	} else {
+		previous, err := gomoddirectives.GetModuleFileFromRev(ctx, cfg.NewFromRev, file.Syntax.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		results = gomoddirectives.AnalyzeDiff(previous, file, opts)
+	}
+
+	if cfg.Base != "" {
+		base, err := readBase(ctx, cfg.Base, file.Syntax.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, gomoddirectives.AnalyzeChanges(base, file, opts)...)
+	}
+
+	return results, nil
+}
+
+// bindFlags binds the flags to the configuration, the current values of the configuration are the default values of the flags.
+func bindFlags(fs *flag.FlagSet, cfg *config) {
+	fs.StringVar(&cfg.ConfigFile, "config", "", "Path of the configuration file (YAML), the flags override the configuration file (default: the .gomoddirectives.yml file of the current directory or its parents)")
+	fs.StringVar(&cfg.Preset, "preset", cfg.Preset, "Preset of options ("+strings.Join(gomoddirectives.PresetNames(), ", ")+"), the configuration file and the flags override the preset")
+	fs.BoolVar(&cfg.ExcludeForbidden, "exclude", cfg.ExcludeForbidden, "Forbid the use of exclude directives")
+	fs.Var(newListFlag(&cfg.ExcludeAllowList), "exclude-list", "List of modules allowed to be excluded")
+	fs.BoolVar(&cfg.ExcludeCheckRequire, "exclude-require", cfg.ExcludeCheckRequire, "Check that excluded modules are required, but not at the excluded version, and detect duplicated exclusions")
+	fs.BoolVar(&cfg.IgnoreForbidden, "ignore", cfg.IgnoreForbidden, "Forbid the use of ignore directives")
+	fs.Var(newListFlag(&cfg.IgnoreAllowList), "ignore-list", "List of allowed ignore directives (patterns)")
+	fs.BoolVar(&cfg.IgnoreCheckPaths, "ignore-paths", cfg.IgnoreCheckPaths, "Check that ignored paths exist and don't contain Go packages of the module")
+	fs.BoolVar(&cfg.ReplaceAllowAll, "all-replace", cfg.ReplaceAllowAll, "Allow all replace directives")
+	fs.Var(newListFlag(&cfg.ReplaceAllowList), "list", "List of allowed replace directives")
+	fs.BoolVar(&cfg.ReplaceAllowLocal, "local", cfg.ReplaceAllowLocal, "Allow local replace directives")
+	fs.BoolVar(&cfg.RetractAllowNoExplanation, "retract-no-explanation", cfg.RetractAllowNoExplanation, "Allow to use retract directives without explanation")
+	fs.StringVar(&cfg.RetractRationalePattern, "retract-pattern", cfg.RetractRationalePattern, "Pattern to validate the explanation of retract directives")
+	fs.BoolVar(&cfg.RetractCheckRanges, "retract-ranges", cfg.RetractCheckRanges, "Check that retract ranges are well-formed, and detect duplicated or overlapping retract directives")
+	fs.BoolVar(&cfg.RetractCheckRelease, "retract-release", cfg.RetractCheckRelease, "Check that the current release (latest local git tag) is not retracted")
+	fs.BoolVar(&cfg.RetractCheckTags, "retract-tags", cfg.RetractCheckTags, "Check that retracted versions match tags of the local git repository")
+	fs.BoolVar(&cfg.ToolchainForbidden, "toolchain", cfg.ToolchainForbidden, "Forbid the use of toolchain directive")
+	fs.StringVar(&cfg.ToolchainPattern, "toolchain-pattern", cfg.ToolchainPattern, "Pattern to validate toolchain directive")
+	fs.BoolVar(&cfg.ToolForbidden, "tool", cfg.ToolForbidden, "Forbid the use of tool directives")
+	fs.Var(newListFlag(&cfg.ToolAllowList), "tool-list", "List of allowed tool directives (patterns)")
+	fs.BoolVar(&cfg.ToolCheckRequire, "tool-require", cfg.ToolCheckRequire, "Check that tools are provided by a required module or the main module, and detect duplicated tools")
+	fs.BoolVar(&cfg.GoDebugForbidden, "godebug", cfg.GoDebugForbidden, "Forbid the use of godebug directives")
+	fs.StringVar(&cfg.GoVersionPattern, "goversion", cfg.GoVersionPattern, "Pattern to validate go min version directive")
+	fs.BoolVar(&cfg.CheckModulePath, "check-module-path", cfg.CheckModulePath, "Check module path validity")
+	fs.BoolVar(&cfg.CheckGoSum, "check-go-sum", cfg.CheckGoSum, "Check the consistency of the go.sum file")
+	fs.BoolVar(&cfg.CheckVendor, "check-vendor", cfg.CheckVendor, "Check the consistency of the vendor/modules.txt file")
+	fs.BoolVar(&cfg.CheckFormat, "check-format", cfg.CheckFormat, "Check that the go.mod file is formatted canonically")
+	fs.BoolVar(&cfg.CheckLayout, "check-layout", cfg.CheckLayout, "Check the order of the directives, the order of the requirements, and the use of a block for the replace directives")
+	fs.Var(newListFlag(&cfg.LayoutOrder), "layout-order", "Order of the directives (module, go, toolchain, godebug, require, require-indirect, replace, exclude, retract, tool, ignore)")
+	fs.StringVar(&cfg.Base, "base", "", "Check the changes (downgrades, new replacements, etc.) compared to a base go.mod file (path or git revision)")
+	fs.StringVar(&cfg.NewFromRev, "new-from-rev", "", "Only report the problems of the directives added or modified since a git revision")
+	fs.BoolVar(&cfg.PureGoDiscovery, "pure-go-discovery", cfg.PureGoDiscovery, "Find the go.mod file without calling the go command")
+	fs.StringVar(&cfg.StdinFilename, "stdin-filename", "go.mod", "Path of the file read from the standard input (-), used for the positions")
+	fs.BoolVar(&cfg.Fix, "fix", false, "Apply the fixes of the problems that can be fixed automatically")
+	fs.Var(newListFlag(&cfg.ModulePathPrefixes), "module-path-prefix", "List of allowed module path prefixes")
+	fs.BoolVar(&cfg.ModulePathCheckVCS, "module-path-vcs", cfg.ModulePathCheckVCS, "Check that the module path matches the origin remote of the local git repository")
+	fs.BoolVar(&cfg.DeprecatedForbidden, "deprecated", cfg.DeprecatedForbidden, "Forbid the deprecation of the module")
+	fs.StringVar(&cfg.DeprecatedPattern, "deprecated-pattern", cfg.DeprecatedPattern, "Pattern to validate the deprecation message of the module")
+	fs.BoolVar(&cfg.RequireCheckDeprecated, "require-deprecated", cfg.RequireCheckDeprecated, "Detect deprecated dependencies (from the local module cache)")
+	fs.BoolVar(&cfg.RequireCheckRetracted, "require-retracted", cfg.RequireCheckRetracted, "Detect required versions retracted by their module (from the local module cache)")
+	fs.BoolVar(&cfg.RequireCheckGoVersion, "require-goversion", cfg.RequireCheckGoVersion, "Check that the go (and toolchain) directive is not lower than the ones of the dependencies (from the local module cache)")
+	fs.BoolVar(&cfg.RequireCheckBlocks, "require-blocks", cfg.RequireCheckBlocks, "Check that direct and indirect requirements are in separate blocks, and that a module is not required with different versions")
+	fs.BoolVar(&cfg.ModulePathCheckMajor, "module-path-major", cfg.ModulePathCheckMajor, "Check that the major version suffix of the module path matches the latest local git tag")
+
+	fs.BoolVar(&cfg.Help, "h", false, "Show this help.")
+}
+
+// moduleOptions computes the options of a module:
+// the configuration of the directory of the module (overrides, configuration files of the directories) is overridden by the flags.
+func moduleOptions(fileCfg *gomoddirectives.Config, root string, in input, args []string) (gomoddirectives.Options, error) {
+	modCfg, err := fileCfg.ForDir(root, filepath.Dir(in.File.Syntax.Name))
+	if err != nil {
+		return gomoddirectives.Options{}, err
+	}
+
+	cfg := config{Config: *modCfg}
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	bindFlags(fs, &cfg)
+
+	err = fs.Parse(args)
+	if err != nil {
+		return gomoddirectives.Options{}, err
+	}
+
+	return cfg.Options()
+}
+
+// readBase reads the base go.mod file from a path, or from a git revision if the path doesn't exist.
+// Returns nil if the go.mod file doesn't exist at the git revision.
+func readBase(ctx context.Context, base, goMod string) (*modfile.File, error) {
+	raw, err := os.ReadFile(filepath.Clean(base))
+	if errors.Is(err, fs.ErrNotExist) {
+		return gomoddirectives.GetModuleFileFromRev(ctx, base, goMod)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return modfile.Parse(base, raw, nil)
+}
+
+// loadConfig loads the configuration file and the preset.
+// The preset (if not empty) replaces the preset of the configuration file.
+func loadConfig(filename, preset string) (*gomoddirectives.Config, error) {
+	if filename != "" {
+		return gomoddirectives.LoadConfigWithPreset(filename, preset)
+	}
+
+	if preset != "" {
+		return gomoddirectives.Preset(preset)
+	}
+
+	return &gomoddirectives.Config{}, nil
+}
+
+// findConfigFile finds the configuration file ([gomoddirectives.ConfigFileName]) in the current directory and its parents.
+// Returns an empty string if the file doesn't exist.
+func findConfigFile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		filename := filepath.Join(dir, gomoddirectives.ConfigFileName)
+
+		info, err := os.Stat(filename)
+		if err == nil && !info.IsDir() {
+			return filename
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// preParseFlags parses the flags without the configuration file, to find the configuration file and the preset.
+// The errors are ignored: they are reported by the parsing of the flags.
+func preParseFlags(args []string) config {
+	cfg := config{}
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	bindFlags(fs, &cfg)
+
+	_ = fs.Parse(args)
+
+	return cfg
+}
+
 func usage() {
 	_, _ = os.Stderr.WriteString(`GoModDirectives
 
-gomoddirectives [flags]
+gomoddirectives [flags] [go.mod|go.work|dir|dir/...|-]...
+
+Without arguments, the go.mod file of the current module is analyzed.
 
 Flags:
 `)
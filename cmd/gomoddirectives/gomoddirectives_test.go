@@ -0,0 +1,130 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ldez/gomoddirectives"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+
+func TestPreParseFlags(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		args           []string
+		expectedConfig string
+		expectedPreset string
+	}{
+		{
+			desc:           "first flags",
+			args:           []string{"-config", "cfg.yml", "-preset=library", "go.mod"},
+			expectedConfig: "cfg.yml",
+			expectedPreset: "library",
+		},
+		{
+			desc:           "after flags with values",
+			args:           []string{"-goversion", ".*", "-list", "a,b", "--config=cfg.yml", "-preset", "strict", "go.mod"},
+			expectedConfig: "cfg.yml",
+			expectedPreset: "strict",
+		},
+		{
+			desc: "after the arguments",
+			args: []string{"go.mod", "-config", "cfg.yml"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := preParseFlags(test.args)
+
+			assert.Equal(t, test.expectedConfig, cfg.ConfigFile)
+			assert.Equal(t, test.expectedPreset, cfg.Preset)
+		})
+	}
+}
+
+func TestModuleOptions(t *testing.T) {
+	root := t.TempDir()
+
+	writeFiles(t, root, map[string]string{
+		gomoddirectives.ConfigFileName: `
+tool-forbidden: true
+replace-allow-list: [example.com/a]
+overrides:
+  tools/**:
+    tool-forbidden: false
+`,
+		"examples/" + gomoddirectives.ConfigFileName: "replace-local: true\n",
+	})
+
+	fileCfg, err := gomoddirectives.LoadConfig(filepath.Join(root, gomoddirectives.ConfigFileName))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		dir      string
+		args     []string
+		expected gomoddirectives.Options
+	}{
+		{
+			desc: "root",
+			dir:  "svc",
+			expected: gomoddirectives.Options{
+				ToolForbidden:    true,
+				ReplaceAllowList: []string{"example.com/a"},
+			},
+		},
+		{
+			desc: "override",
+			dir:  "tools/gen",
+			expected: gomoddirectives.Options{
+				ReplaceAllowList: []string{"example.com/a"},
+			},
+		},
+		{
+			desc: "configuration file of a directory",
+			dir:  "examples/demo",
+			expected: gomoddirectives.Options{
+				ToolForbidden:     true,
+				ReplaceAllowList:  []string{"example.com/a"},
+				ReplaceAllowLocal: true,
+			},
+		},
+		{
+			desc: "flags override the configuration of the directory",
+			dir:  "examples/demo",
+			args: []string{"-local=false", "-list", "example.com/b", "-list", "example.com/c", "-check-format"},
+			expected: gomoddirectives.Options{
+				ToolForbidden:    true,
+				ReplaceAllowList: []string{"example.com/b", "example.com/c"},
+				CheckFormat:      true,
+			},
+		},
+		{
+			desc: "flags override the overrides",
+			dir:  "tools/gen",
+			args: []string{"-tool", "go.mod"},
+			expected: gomoddirectives.Options{
+				ToolForbidden:    true,
+				ReplaceAllowList: []string{"example.com/a"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			in := input{File: &modfile.File{Syntax: &modfile.FileSyntax{Name: filepath.Join(root, filepath.FromSlash(test.dir), "go.mod")}}}
+
+			opts, err := moduleOptions(fileCfg, root, in, test.args)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, opts)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ldez/gomoddirectives"
+	"golang.org/x/mod/modfile"
+)
+
+// input a module file to analyze.
+type input struct {
+	File *modfile.File
+	// Content the content of the file read from the standard input (nil if the file is read from the disk).
+	Content []byte
+}
+
+// readInputs reads the module files defined by the arguments:
+// go.mod files, go.work files (the modules used by the workspace), directories, or `-` for the standard input.
+// Without arguments, the module file of the current directory is read.
+func readInputs(ctx context.Context, cfg config, args []string) ([]input, error) {
+	if len(args) == 0 {
+		file, err := currentModuleFile(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get module file: %w", err)
+		}
+
+		return []input{{File: file}}, nil
+	}
+
+	var (
+		inputs []input
+		stdin  bool
+	)
+
+	for _, arg := range args {
+		if arg == "-" {
+			if stdin {
+				return nil, errors.New("the standard input can be used only once")
+			}
+
+			stdin = true
+
+			in, err := readStdin(cfg.StdinFilename)
+			if err != nil {
+				return nil, err
+			}
+
+			inputs = append(inputs, in)
+
+			continue
+		}
+
+		goMods, err := resolveArg(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, goMod := range goMods {
+			file, err := gomoddirectives.GetModuleFileFromPath(goMod)
+			if err != nil {
+				return nil, err
+			}
+
+			inputs = append(inputs, input{File: file})
+		}
+	}
+
+	return inputs, nil
+}
+
+func currentModuleFile(ctx context.Context, cfg config) (*modfile.File, error) {
+	if cfg.PureGoDiscovery {
+		return gomoddirectives.DiscoverModuleFile(ctx, ".")
+	}
+
+	return gomoddirectives.GetModuleFileContext(ctx)
+}
+
+func readStdin(filename string) (input, error) {
+	raw, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return input{}, fmt.Errorf("read the standard input: %w", err)
+	}
+
+	file, err := modfile.Parse(filename, raw, nil)
+	if err != nil {
+		return input{}, err
+	}
+
+	return input{File: file, Content: raw}, nil
+}
+
+// resolveArg returns the paths of the go.mod files defined by an argument.
+func resolveArg(arg string) ([]string, error) {
+	if dir, ok := strings.CutSuffix(filepath.ToSlash(arg), "..."); ok && (dir == "" || strings.HasSuffix(dir, "/")) {
+		return scanGoMods(cmp.Or(dir, "."))
+	}
+
+	info, err := os.Stat(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		if strings.HasSuffix(arg, ".work") {
+			return workspaceGoMods(arg)
+		}
+
+		return []string{arg}, nil
+	}
+
+	goMod := filepath.Join(arg, "go.mod")
+
+	_, err = os.Stat(goMod)
+	if err == nil {
+		return []string{goMod}, nil
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	goWork := filepath.Join(arg, "go.work")
+
+	_, err = os.Stat(goWork)
+	if err == nil {
+		return workspaceGoMods(goWork)
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("no go.mod or go.work file in %s", arg)
+}
+
+// scanGoMods returns the paths of the go.mod files of a directory and its subdirectories (ex: `./...`).
+// The vendor and testdata directories, and the directories starting with `.` or `_`, are skipped (like the go command).
+func scanGoMods(dir string) ([]string, error) {
+	root := filepath.Clean(filepath.FromSlash(dir))
+
+	var goMods []string
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.Name() == "go.mod" {
+			goMods = append(goMods, p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(goMods) == 0 {
+		return nil, fmt.Errorf("no go.mod file in %s", dir)
+	}
+
+	return goMods, nil
+}
+
+// workspaceGoMods returns the paths of the go.mod files of the modules used by a workspace.
+func workspaceGoMods(goWork string) ([]string, error) {
+	raw, err := os.ReadFile(filepath.Clean(goWork))
+	if err != nil {
+		return nil, err
+	}
+
+	work, err := modfile.ParseWork(goWork, raw, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var goMods []string
+
+	for _, use := range work.Use {
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(goWork), dir)
+		}
+
+		goMods = append(goMods, filepath.Join(dir, "go.mod"))
+	}
+
+	return goMods, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o750))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+	}
+}
+
+func TestResolveArg_scan(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                "module example.com/root\n",
+		"svc/go.mod":            "module example.com/svc\n",
+		"tools/gen/go.mod":      "module example.com/tools/gen\n",
+		"svc/vendor/x/go.mod":   "module example.com/x\n",
+		"testdata/a/go.mod":     "module example.com/a\n",
+		".hidden/go.mod":        "module example.com/hidden\n",
+		"_old/go.mod":           "module example.com/old\n",
+		"examples/demo/main.go": "package main\n",
+	})
+
+	goMods, err := resolveArg(filepath.Join(dir, "..."))
+	require.NoError(t, err)
+
+	expected := []string{
+		filepath.Join(dir, "go.mod"),
+		filepath.Join(dir, "svc", "go.mod"),
+		filepath.Join(dir, "tools", "gen", "go.mod"),
+	}
+
+	assert.Equal(t, expected, goMods)
+
+	goMods, err = resolveArg(filepath.Join(dir, "tools") + "/...")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(dir, "tools", "gen", "go.mod")}, goMods)
+
+	_, err = resolveArg(filepath.Join(dir, "examples", "..."))
+	require.ErrorContains(t, err, "no go.mod file")
+}
+
+func TestResolveArg(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"mod/go.mod":   "module example.com/mod\n",
+		"work/go.work": "go 1.22\n\nuse (\n\t./a\n\t./b\n)\n",
+	})
+
+	goMods, err := resolveArg(filepath.Join(dir, "mod"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(dir, "mod", "go.mod")}, goMods)
+
+	goMods, err = resolveArg(filepath.Join(dir, "work"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(dir, "work", "a", "go.mod"), filepath.Join(dir, "work", "b", "go.mod")}, goMods)
+
+	_, err = resolveArg(dir)
+	require.ErrorContains(t, err, "no go.mod or go.work file")
+}
@@ -0,0 +1,264 @@
+package gomoddirectives
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config the configuration of gomoddirectives (ex: the content of a configuration file).
+// The names of the fields are the names of the golangci-lint settings.
+type Config struct {
+	Preset                    string         `yaml:"preset"`
+	ReplaceAllowAll           bool           `yaml:"replace-allow-all"`
+	ReplaceAllowList          []string       `yaml:"replace-allow-list"`
+	ReplaceAllowLocal         bool           `yaml:"replace-local"`
+	ExcludeForbidden          bool           `yaml:"exclude-forbidden"`
+	ExcludeAllowList          []string       `yaml:"exclude-allow-list"`
+	ExcludeCheckRequire       bool           `yaml:"exclude-check-require"`
+	IgnoreForbidden           bool           `yaml:"ignore-forbidden"`
+	IgnoreAllowList           []string       `yaml:"ignore-allow-list"`
+	IgnoreCheckPaths          bool           `yaml:"ignore-check-paths"`
+	RetractAllowNoExplanation bool           `yaml:"retract-allow-no-explanation"`
+	RetractRationalePattern   string         `yaml:"retract-rationale-pattern"`
+	RetractCheckRanges        bool           `yaml:"retract-check-ranges"`
+	RetractCheckRelease       bool           `yaml:"retract-check-release"`
+	RetractCheckTags          bool           `yaml:"retract-check-tags"`
+	ToolchainForbidden        bool           `yaml:"toolchain-forbidden"`
+	ToolchainPattern          string         `yaml:"toolchain-pattern"`
+	ToolForbidden             bool           `yaml:"tool-forbidden"`
+	ToolAllowList             []string       `yaml:"tool-allow-list"`
+	ToolCheckRequire          bool           `yaml:"tool-check-require"`
+	GoDebugForbidden          bool           `yaml:"go-debug-forbidden"`
+	GoVersionPattern          string         `yaml:"go-version-pattern"`
+	CheckModulePath           bool           `yaml:"check-module-path"`
+	ModulePathPrefixes        []string       `yaml:"module-path-prefixes"`
+	ModulePathCheckVCS        bool           `yaml:"module-path-check-vcs"`
+	ModulePathCheckMajor      bool           `yaml:"module-path-check-major"`
+	DeprecatedForbidden       bool           `yaml:"deprecated-forbidden"`
+	DeprecatedPattern         string         `yaml:"deprecated-pattern"`
+	RequireCheckDeprecated    bool           `yaml:"require-check-deprecated"`
+	RequireCheckRetracted     bool           `yaml:"require-check-retracted"`
+	RequireCheckGoVersion     bool           `yaml:"require-check-go-version"`
+	RequireCheckBlocks        bool           `yaml:"require-check-blocks"`
+	CheckGoSum                bool           `yaml:"check-go-sum"`
+	CheckVendor               bool           `yaml:"check-vendor"`
+	CheckFormat               bool           `yaml:"check-format"`
+	CheckLayout               bool           `yaml:"check-layout"`
+	LayoutOrder               []string       `yaml:"layout-order"`
+	PureGoDiscovery           bool           `yaml:"pure-go-discovery"`
+	Policies                  []PolicyConfig `yaml:"policies"`
+	Overrides                 Overrides      `yaml:"overrides"`
+	RuleSettings              map[string]any `yaml:"rule-settings"`
+}
+
+// PolicyConfig the configuration of a declarative rule (see [Policy]).
+type PolicyConfig struct {
+	Name        string   `yaml:"name"`
+	Directives  []string `yaml:"directives"`
+	Path        string   `yaml:"path"`
+	Replacement string   `yaml:"replacement"`
+	Version     string   `yaml:"version"`
+	Comment     string   `yaml:"comment"`
+	Message     string   `yaml:"message"`
+	Severity    string   `yaml:"severity"`
+}
+
+// LoadConfig loads a configuration file (YAML).
+// The values of the preset of the file (`preset`) are the default values of the configuration.
+// The unknown fields are errors.
+func LoadConfig(filename string) (*Config, error) {
+	return LoadConfigWithPreset(filename, "")
+}
+
+// LoadConfigWithPreset loads a configuration file (YAML),
+// the preset (if not empty) replaces the preset of the file.
+func LoadConfigWithPreset(filename, preset string) (*Config, error) {
+	raw, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	if preset == "" {
+		preset, err = decodePreset(raw)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", filename, err)
+		}
+	}
+
+	cfg := &Config{}
+
+	if preset != "" {
+		cfg, err = Preset(preset)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+
+	err = decodeConfig(raw, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", filename, err)
+	}
+
+	cfg.Preset = preset
+
+	return cfg, nil
+}
+
+// decodePreset decodes the name of the preset of a YAML configuration.
+func decodePreset(raw []byte) (string, error) {
+	var probe struct {
+		Preset string `yaml:"preset"`
+	}
+
+	err := yaml.Unmarshal(raw, &probe)
+	if err != nil {
+		return "", err
+	}
+
+	return probe.Preset, nil
+}
+
+// decodeConfig decodes a YAML configuration over the existing values of the configuration.
+func decodeConfig(raw []byte, cfg *Config) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(cfg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
+}
+
+// Options creates the analyzer options from the configuration.
+func (c *Config) Options() (Options, error) {
+	opts := Options{
+		ReplaceAllowAll:           c.ReplaceAllowAll,
+		ReplaceAllowList:          c.ReplaceAllowList,
+		ReplaceAllowLocal:         c.ReplaceAllowLocal,
+		ExcludeForbidden:          c.ExcludeForbidden,
+		ExcludeAllowList:          c.ExcludeAllowList,
+		ExcludeCheckRequire:       c.ExcludeCheckRequire,
+		IgnoreForbidden:           c.IgnoreForbidden,
+		IgnoreAllowList:           c.IgnoreAllowList,
+		IgnoreCheckPaths:          c.IgnoreCheckPaths,
+		RetractAllowNoExplanation: c.RetractAllowNoExplanation,
+		RetractCheckRanges:        c.RetractCheckRanges,
+		RetractCheckRelease:       c.RetractCheckRelease,
+		RetractCheckTags:          c.RetractCheckTags,
+		ToolchainForbidden:        c.ToolchainForbidden,
+		ToolForbidden:             c.ToolForbidden,
+		ToolAllowList:             c.ToolAllowList,
+		ToolCheckRequire:          c.ToolCheckRequire,
+		GoDebugForbidden:          c.GoDebugForbidden,
+		CheckModulePath:           c.CheckModulePath,
+		ModulePathPrefixes:        c.ModulePathPrefixes,
+		ModulePathCheckVCS:        c.ModulePathCheckVCS,
+		ModulePathCheckMajor:      c.ModulePathCheckMajor,
+		DeprecatedForbidden:       c.DeprecatedForbidden,
+		RequireCheckDeprecated:    c.RequireCheckDeprecated,
+		RequireCheckRetracted:     c.RequireCheckRetracted,
+		RequireCheckGoVersion:     c.RequireCheckGoVersion,
+		RequireCheckBlocks:        c.RequireCheckBlocks,
+		CheckGoSum:                c.CheckGoSum,
+		CheckVendor:               c.CheckVendor,
+		CheckFormat:               c.CheckFormat,
+		CheckLayout:               c.CheckLayout,
+		LayoutOrder:               c.LayoutOrder,
+		PureGoDiscovery:           c.PureGoDiscovery,
+		RuleSettings:              c.RuleSettings,
+	}
+
+	err := compilePatterns([]namedPattern{
+		{name: "retract-rationale-pattern", pattern: c.RetractRationalePattern, re: &opts.RetractRationalePattern},
+		{name: "toolchain-pattern", pattern: c.ToolchainPattern, re: &opts.ToolchainPattern},
+		{name: "go-version-pattern", pattern: c.GoVersionPattern, re: &opts.GoVersionPattern},
+		{name: "deprecated-pattern", pattern: c.DeprecatedPattern, re: &opts.DeprecatedPattern},
+	})
+	if err != nil {
+		return Options{}, err
+	}
+
+	for i, pc := range c.Policies {
+		policy, err := pc.Policy()
+		if err != nil {
+			return Options{}, fmt.Errorf("policies[%d]: %w", i, err)
+		}
+
+		opts.Policies = append(opts.Policies, policy)
+	}
+
+	return opts, nil
+}
+
+// Policy creates the policy from the configuration.
+func (c PolicyConfig) Policy() (Policy, error) {
+	severity, err := ParseSeverity(c.Severity)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	for _, directive := range c.Directives {
+		if !slices.Contains(PolicyDirectives, directive) {
+			return Policy{}, fmt.Errorf("unknown directive: %q", directive)
+		}
+	}
+
+	_, err = parseVersionConstraint(c.Version)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	policy := Policy{
+		Name:       c.Name,
+		Directives: c.Directives,
+		Version:    c.Version,
+		Message:    c.Message,
+		Severity:   severity,
+	}
+
+	err = compilePatterns([]namedPattern{
+		{name: "path", pattern: c.Path, re: &policy.Path},
+		{name: "replacement", pattern: c.Replacement, re: &policy.Replacement},
+		{name: "comment", pattern: c.Comment, re: &policy.Comment},
+	})
+	if err != nil {
+		return Policy{}, err
+	}
+
+	return policy, nil
+}
+
+// namedPattern a pattern of the configuration, and the destination of the compiled pattern.
+type namedPattern struct {
+	name    string
+	pattern string
+	re      **regexp.Regexp
+}
+
+// compilePatterns compiles the non-empty patterns.
+func compilePatterns(patterns []namedPattern) error {
+	for _, p := range patterns {
+		if p.pattern == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(p.pattern)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.name, err)
+		}
+
+		*p.re = re
+	}
+
+	return nil
+}
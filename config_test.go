@@ -0,0 +1,130 @@
+package gomoddirectives
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, dir, content string) string {
+	t.Helper()
+
+	filename := filepath.Join(dir, ConfigFileName)
+
+	require.NoError(t, os.MkdirAll(dir, 0o750))
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+
+	return filename
+}
+
+func TestLoadConfig(t *testing.T) {
+	filename := writeConfigFile(t, t.TempDir(), `
+replace-local: true
+replace-allow-list:
+  - example.com/a
+go-version-pattern: '1\.2\d$'
+rule-settings:
+  foo:
+    max: 3
+`)
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+
+	expected := &Config{
+		ReplaceAllowList:  []string{"example.com/a"},
+		ReplaceAllowLocal: true,
+		GoVersionPattern:  `1\.2\d$`,
+		RuleSettings:      map[string]any{"foo": map[string]any{"max": 3}},
+	}
+
+	assert.Equal(t, expected, cfg)
+
+	opts, err := cfg.Options()
+	require.NoError(t, err)
+
+	assert.True(t, opts.ReplaceAllowLocal)
+	assert.Equal(t, []string{"example.com/a"}, opts.ReplaceAllowList)
+	require.NotNil(t, opts.GoVersionPattern)
+	assert.Equal(t, `1\.2\d$`, opts.GoVersionPattern.String())
+	assert.Nil(t, opts.ToolchainPattern)
+}
+
+func TestLoadConfig_empty(t *testing.T) {
+	cfg, err := LoadConfig(writeConfigFile(t, t.TempDir(), ""))
+	require.NoError(t, err)
+
+	assert.Equal(t, &Config{}, cfg)
+}
+
+func TestLoadConfig_unknownField(t *testing.T) {
+	_, err := LoadConfig(writeConfigFile(t, t.TempDir(), "replace-all: true\n"))
+	require.Error(t, err)
+}
+
+func TestConfig_Options_invalidPattern(t *testing.T) {
+	cfg := &Config{ToolchainPattern: `go1\.(`}
+
+	_, err := cfg.Options()
+	require.ErrorContains(t, err, "toolchain-pattern")
+}
+
+func TestLoadConfig_policies(t *testing.T) {
+	filename := writeConfigFile(t, t.TempDir(), `
+policies:
+  - name: no-fork
+    directives: [replace]
+    path: '^example\.com/'
+    replacement: '^github\.com/'
+    message: the internal modules must not be replaced by public forks
+    severity: warning
+  - directives: [require]
+    path: '^example\.com/z$'
+    version: '<v1.5.0'
+    message: example.com/z must be >= v1.5.0
+`)
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+
+	opts, err := cfg.Options()
+	require.NoError(t, err)
+
+	require.Len(t, opts.Policies, 2)
+
+	assert.Equal(t, "no-fork", opts.Policies[0].Name)
+	assert.Equal(t, []string{"replace"}, opts.Policies[0].Directives)
+	assert.Equal(t, `^github\.com/`, opts.Policies[0].Replacement.String())
+	assert.Equal(t, SeverityWarning, opts.Policies[0].Severity)
+	assert.Nil(t, opts.Policies[0].Comment)
+
+	assert.Equal(t, "<v1.5.0", opts.Policies[1].Version)
+	assert.Equal(t, SeverityError, opts.Policies[1].Severity)
+}
+
+func TestConfig_Options_invalidPolicy(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		policy PolicyConfig
+		errMsg string
+	}{
+		{desc: "directive", policy: PolicyConfig{Directives: []string{"requires"}}, errMsg: `policies[0]: unknown directive: "requires"`},
+		{desc: "severity", policy: PolicyConfig{Severity: "fatal"}, errMsg: `policies[0]: unknown severity: "fatal"`},
+		{desc: "version", policy: PolicyConfig{Version: ">=latest"}, errMsg: `policies[0]: invalid version constraint ">=latest"`},
+		{desc: "pattern", policy: PolicyConfig{Path: `example.com/(`}, errMsg: "policies[0]: path: "},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{Policies: []PolicyConfig{test.policy}}
+
+			_, err := cfg.Options()
+			require.ErrorContains(t, err, test.errMsg)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package gomoddirectives
+
+import (
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// AnalyzeDiff analyzes a mod file, but only reports the results related to the directives
+// that are new or modified compared to the old version of the mod file.
+// If the old version is nil, all the directives are new.
+func AnalyzeDiff(old, file *modfile.File, opts Options) []Result {
+	changed := changedLines(old, file)
+
+	var results []Result
+
+	for _, result := range AnalyzeFile(file, opts) {
+		if result.Start.Filename != file.Syntax.Name {
+			continue
+		}
+
+		for line := result.Start.Line; line <= max(result.End.Line, result.Start.Line); line++ {
+			if changed[line] {
+				results = append(results, result)
+				break
+			}
+		}
+	}
+
+	return results
+}
+
+// changedLines returns the line numbers of the directives of the new file that are not in the old file.
+// A directive is identified by its verb, its tokens, and its comments.
+func changedLines(old, file *modfile.File) map[int]bool {
+	known := map[string]int{}
+
+	if old != nil {
+		for _, key := range directiveKeys(old) {
+			known[key.Key]++
+		}
+	}
+
+	changed := map[int]bool{}
+
+	for _, key := range directiveKeys(file) {
+		if known[key.Key] > 0 {
+			known[key.Key]--
+			continue
+		}
+
+		for line := key.Start; line <= key.End; line++ {
+			changed[line] = true
+		}
+	}
+
+	return changed
+}
+
+// directiveKey the identity of a directive and its position.
+type directiveKey struct {
+	Key        string
+	Start, End int
+}
+
+func directiveKeys(file *modfile.File) []directiveKey {
+	var keys []directiveKey
+
+	for _, stmt := range file.Syntax.Stmt {
+		switch x := stmt.(type) {
+		case *modfile.Line:
+			keys = append(keys, newDirectiveKey(x.Token[0], x.Token[1:], x))
+
+		case *modfile.LineBlock:
+			for _, line := range x.Line {
+				keys = append(keys, newDirectiveKey(x.Token[0], line.Token, line))
+			}
+		}
+	}
+
+	return keys
+}
+
+// newDirectiveKey creates the key of a directive.
+// A directive inside a block and the same directive outside a block have the same key.
+func newDirectiveKey(verb string, tokens []string, line *modfile.Line) directiveKey {
+	parts := []string{verb, strings.Join(tokens, " ")}
+
+	for _, comments := range [][]modfile.Comment{line.Before, line.Suffix} {
+		for _, comment := range comments {
+			parts = append(parts, comment.Token)
+		}
+	}
+
+	return directiveKey{
+		Key:   strings.Join(parts, "\n"),
+		Start: line.Start.Line,
+		End:   line.End.Line,
+	}
+}
@@ -0,0 +1,139 @@
+package gomoddirectives
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+
+const baseDiffGoMod = `module example.com/foo
+
+go 1.22
+
+require (
+	github.com/a/a v1.0.0
+	github.com/b/b v1.0.0
+)
+
+replace github.com/a/a => ../a
+`
+
+const headDiffGoMod = `module example.com/foo
+
+go 1.22
+
+require (
+	github.com/a/a v1.0.0
+	github.com/b/b v1.0.0
+)
+
+replace (
+	github.com/a/a => ../a
+	github.com/b/b => ../b
+)
+
+exclude github.com/b/b v0.1.0
+`
+
+func TestAnalyzeDiff(t *testing.T) {
+	base, err := modfile.Parse("go.mod", []byte(baseDiffGoMod), nil)
+	require.NoError(t, err)
+
+	head, err := modfile.Parse("go.mod", []byte(headDiffGoMod), nil)
+	require.NoError(t, err)
+
+	opts := Options{ExcludeForbidden: true}
+
+	testCases := []struct {
+		desc     string
+		base     *modfile.File
+		expected []string
+	}{
+		{
+			desc: "new directives",
+			base: base,
+			expected: []string{
+				"go.mod:15:1: exclude directive is not allowed",
+				"go.mod:12:2: local replacement are not allowed: github.com/b/b",
+			},
+		},
+		{
+			desc: "without base",
+			expected: []string{
+				"go.mod:15:1: exclude directive is not allowed",
+				"go.mod:11:2: local replacement are not allowed: github.com/a/a",
+				"go.mod:12:2: local replacement are not allowed: github.com/b/b",
+			},
+		},
+		{
+			desc: "same file",
+			base: head,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var messages []string
+			for _, result := range AnalyzeDiff(test.base, head, opts) {
+				messages = append(messages, result.String())
+			}
+
+			assert.Equal(t, test.expected, messages)
+		})
+	}
+}
+
+func TestGetModuleFileFromRev(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+
+	goMod := filepath.Join(dir, "go.mod")
+
+	err := os.WriteFile(goMod, []byte(baseDiffGoMod), 0o600)
+	require.NoError(t, err)
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "go.mod"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--no-gpg-sign", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	err = os.WriteFile(goMod, []byte(headDiffGoMod), 0o600)
+	require.NoError(t, err)
+
+	file, err := GetModuleFileFromRev(t.Context(), "HEAD", goMod)
+	require.NoError(t, err)
+
+	assert.Len(t, file.Replace, 1)
+
+	_, err = GetModuleFileFromRev(t.Context(), "unknown", goMod)
+	require.Error(t, err)
+
+	// the module doesn't exist at the revision.
+	newDir := filepath.Join(dir, "sub")
+	require.NoError(t, os.MkdirAll(newDir, 0o750))
+
+	newGoMod := filepath.Join(newDir, "go.mod")
+	require.NoError(t, os.WriteFile(newGoMod, []byte(headDiffGoMod), 0o600))
+
+	file, err = GetModuleFileFromRev(t.Context(), "HEAD", newGoMod)
+	require.NoError(t, err)
+
+	assert.Nil(t, file)
+}
@@ -0,0 +1,204 @@
+package gomoddirectives
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/analysis"
+)
+
+// discoveryCache the results of the discovery, by directory and environment.
+var discoveryCache sync.Map
+
+// moduleFiles the module files of a directory.
+type moduleFiles struct {
+	// GoMod the path of the go.mod file of the module that contains the directory.
+	GoMod string
+	// GoWork the path of the go.work file (empty if the workspace mode is disabled).
+	GoWork string
+}
+
+// discoveryKey the directory and the overrides of the environment, the paths are absolute.
+type discoveryKey struct {
+	Dir     string
+	GoWork  string
+	ModFile string
+}
+
+// discoverModuleFiles finds the module files of a directory without calling the go command:
+// the go.mod and the go.work files are searched in the directory and its parents.
+// The GOWORK and GOFLAGS (-modfile) environment variables are honored.
+// The results are cached by directory.
+func discoverModuleFiles(ctx context.Context, dir string) (moduleFiles, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return moduleFiles{}, err
+	}
+
+	key := discoveryKey{Dir: dir, GoWork: os.Getenv("GOWORK"), ModFile: modFileFlag(os.Getenv("GOFLAGS"))}
+
+	if key.GoWork != "" && key.GoWork != "off" {
+		key.GoWork, err = filepath.Abs(key.GoWork)
+		if err != nil {
+			return moduleFiles{}, err
+		}
+	}
+
+	if cached, ok := discoveryCache.Load(key); ok {
+		if files, ok := cached.(moduleFiles); ok {
+			return files, nil
+		}
+	}
+
+	files := moduleFiles{GoMod: key.ModFile}
+
+	if files.GoMod == "" {
+		files.GoMod, err = findUp(ctx, dir, "go.mod")
+		if err != nil {
+			return moduleFiles{}, err
+		}
+	}
+
+	switch key.GoWork {
+	case "off":
+	case "":
+		files.GoWork, err = findUp(ctx, dir, "go.work")
+		if err != nil {
+			return moduleFiles{}, err
+		}
+
+	default:
+		files.GoWork = key.GoWork
+	}
+
+	if files.GoMod == "" {
+		return moduleFiles{}, fmt.Errorf("go.mod file not found in %s or any parent directory", dir)
+	}
+
+	discoveryCache.Store(key, files)
+
+	return files, nil
+}
+
+// modFileFlag returns the absolute path of the file defined by the -modfile flag of GOFLAGS.
+func modFileFlag(goFlags string) string {
+	for _, f := range strings.Fields(goFlags) {
+		value, ok := strings.CutPrefix(strings.TrimLeft(f, "-"), "modfile=")
+		if !ok {
+			continue
+		}
+
+		abs, err := filepath.Abs(value)
+		if err != nil {
+			return value
+		}
+
+		return abs
+	}
+
+	return ""
+}
+
+// findUp finds a file in a directory and its parents.
+// Returns an empty string if the file doesn't exist.
+func findUp(ctx context.Context, dir, name string) (string, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		candidate := filepath.Join(dir, name)
+
+		info, err := os.Stat(candidate)
+		if err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// workspaceGoMod finds the go.mod file of a module used by a workspace.
+// Returns an empty string if the module is not used by the workspace.
+func workspaceGoMod(ctx context.Context, goWork, modPath string) (string, error) {
+	raw, err := os.ReadFile(filepath.Clean(goWork))
+	if err != nil {
+		return "", err
+	}
+
+	work, err := modfile.ParseWork(goWork, raw, nil)
+	if err != nil {
+		return "", err
+	}
+
+	for _, use := range work.Use {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(goWork), dir)
+		}
+
+		file, err := readLocalGoMod(dir)
+		if err != nil || file.Module == nil {
+			continue
+		}
+
+		if file.Module.Mod.Path == modPath {
+			return filepath.Join(dir, "go.mod"), nil
+		}
+	}
+
+	return "", nil
+}
+
+// discoverPassGoMod finds the go.mod file of the module of a pass without calling the go command.
+func discoverPassGoMod(ctx context.Context, pass *analysis.Pass) (string, error) {
+	files, err := discoverModuleFiles(ctx, passDir(pass))
+	if err != nil {
+		return "", err
+	}
+
+	if files.GoWork == "" || pass.Module == nil || pass.Module.Path == "" {
+		return files.GoMod, nil
+	}
+
+	goMod, err := workspaceGoMod(ctx, files.GoWork, pass.Module.Path)
+	if err != nil {
+		return "", err
+	}
+
+	if goMod == "" {
+		return files.GoMod, nil
+	}
+
+	return goMod, nil
+}
+
+// passDir returns the directory of the package of a pass.
+func passDir(pass *analysis.Pass) string {
+	for _, f := range pass.Files {
+		if tf := pass.Fset.File(f.Pos()); tf != nil {
+			return filepath.Dir(tf.Name())
+		}
+	}
+
+	return "."
+}
@@ -0,0 +1,100 @@
+package gomoddirectives
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWorkspace(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	files := map[string]string{
+		"go.work":            "go 1.24\n\nuse (\n\t./a\n\t./b\n)\n",
+		"a/go.mod":           "module example.com/a\n\ngo 1.24\n",
+		"a/alt.mod":          "module example.com/alt\n\ngo 1.24\n",
+		"a/sub/dir/file.txt": "",
+		"b/go.mod":           "module example.com/b\n\ngo 1.24\n",
+	}
+
+	for name, content := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o750))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+	}
+
+	return root
+}
+
+func Test_discoverModuleFiles(t *testing.T) {
+	root := setupWorkspace(t)
+
+	t.Setenv("GOWORK", "")
+	t.Setenv("GOFLAGS", "")
+
+	files, err := discoverModuleFiles(context.Background(), filepath.Join(root, "a", "sub", "dir"))
+	require.NoError(t, err)
+
+	expected := moduleFiles{
+		GoMod:  filepath.Join(root, "a", "go.mod"),
+		GoWork: filepath.Join(root, "go.work"),
+	}
+
+	assert.Equal(t, expected, files)
+}
+
+func Test_discoverModuleFiles_goWorkOff(t *testing.T) {
+	root := setupWorkspace(t)
+
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "")
+
+	files, err := discoverModuleFiles(context.Background(), filepath.Join(root, "b"))
+	require.NoError(t, err)
+
+	assert.Equal(t, moduleFiles{GoMod: filepath.Join(root, "b", "go.mod")}, files)
+}
+
+func Test_discoverModuleFiles_modFile(t *testing.T) {
+	root := setupWorkspace(t)
+
+	t.Chdir(filepath.Join(root, "a"))
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "-mod=mod -modfile=alt.mod")
+
+	file, err := DiscoverModuleFile(context.Background(), filepath.Join(root, "a", "sub"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com/alt", file.Module.Mod.Path)
+}
+
+func Test_discoverModuleFiles_canceled(t *testing.T) {
+	root := setupWorkspace(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := discoverModuleFiles(ctx, filepath.Join(root, "a", "sub", "dir"))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_workspaceGoMod(t *testing.T) {
+	root := setupWorkspace(t)
+
+	goMod, err := workspaceGoMod(context.Background(), filepath.Join(root, "go.work"), "example.com/b")
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(root, "b", "go.mod"), goMod)
+
+	goMod, err = workspaceGoMod(context.Background(), filepath.Join(root, "go.work"), "example.com/unknown")
+	require.NoError(t, err)
+
+	assert.Empty(t, goMod)
+}
@@ -0,0 +1,221 @@
+package gomoddirectives
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// TextEdit a replacement of the bytes [Start, End) of the analyzed file by NewText.
+type TextEdit struct {
+	Start   int
+	End     int
+	NewText []byte
+}
+
+// hunk a group of consecutive changed lines: the old lines [OldStart, OldEnd) are replaced by the new lines [NewStart, NewEnd).
+type hunk struct {
+	OldStart, OldEnd int
+	NewStart, NewEnd int
+}
+
+// checkFormat compares the module file with its canonical formatting ([modfile.Format]).
+func checkFormat(file *modfile.File, opts Options) []Result {
+	if !opts.CheckFormat {
+		return nil
+	}
+
+	filename := file.Syntax.Name
+
+	raw, err := readContent(file, opts)
+	if err != nil {
+		return []Result{{
+			Reason: err.Error(),
+			Start:  token.Position{Filename: filename, Line: 1, Column: 1},
+			End:    token.Position{Filename: filename, Line: 1, Column: 1},
+		}}
+	}
+
+	return formatResults(filename, raw, modfile.Format(file.Syntax))
+}
+
+// readContent returns the content of the module file.
+// The content is read from the path of the parsed file, unless it is provided by the options ([Options.WithContent]).
+func readContent(file *modfile.File, opts Options) ([]byte, error) {
+	if opts.content != nil {
+		return opts.content, nil
+	}
+
+	return os.ReadFile(filepath.Clean(file.Syntax.Name))
+}
+
+func formatResults(filename string, raw, formatted []byte) []Result {
+	if bytes.Equal(raw, formatted) {
+		return nil
+	}
+
+	oldLines := splitLines(raw)
+	newLines := splitLines(formatted)
+
+	offsets := make([]int, len(oldLines)+1)
+	for i, line := range oldLines {
+		offsets[i+1] = offsets[i] + len(line)
+	}
+
+	var results []Result
+
+	for _, h := range diffLines(oldLines, newLines) {
+		startLine, endLine := h.OldStart+1, max(h.OldEnd, h.OldStart+1)
+
+		// insertion at the end of the file.
+		if startLine > len(oldLines) {
+			startLine, endLine = max(len(oldLines), 1), max(len(oldLines), 1)
+		}
+
+		endColumn := 1
+		if endLine <= len(oldLines) {
+			endColumn = len(strings.TrimRight(oldLines[endLine-1], "\r\n")) + 1
+		}
+
+		results = append(results, Result{
+			Reason: fmt.Sprintf("%s:\n%s", reasonFormat, h.String(oldLines, newLines)),
+			Start:  token.Position{Filename: filename, Line: startLine, Column: 1},
+			End:    token.Position{Filename: filename, Line: endLine, Column: endColumn},
+			Edits: []TextEdit{{
+				Start:   offsets[h.OldStart],
+				End:     offsets[h.OldEnd],
+				NewText: []byte(strings.Join(newLines[h.NewStart:h.NewEnd], "")),
+			}},
+		})
+	}
+
+	return results
+}
+
+// String returns the hunk in the unified diff format.
+func (h hunk) String(oldLines, newLines []string) string {
+	b := &strings.Builder{}
+
+	_, _ = fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", h.OldStart+1, h.OldEnd-h.OldStart, h.NewStart+1, h.NewEnd-h.NewStart)
+
+	for _, line := range oldLines[h.OldStart:h.OldEnd] {
+		_, _ = fmt.Fprintf(b, "-%s\n", strings.TrimRight(line, "\r\n"))
+	}
+
+	for _, line := range newLines[h.NewStart:h.NewEnd] {
+		_, _ = fmt.Fprintf(b, "+%s\n", strings.TrimRight(line, "\r\n"))
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// splitLines splits the content into lines, the line endings are kept.
+func splitLines(raw []byte) []string {
+	lines := strings.SplitAfter(string(raw), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines computes the changed lines between 2 lists of lines (longest common subsequence).
+func diffLines(oldLines, newLines []string) []hunk {
+	n, m := len(oldLines), len(newLines)
+
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var (
+		hunks   []hunk
+		current *hunk
+	)
+
+	flush := func(i, j int) {
+		if current != nil {
+			current.OldEnd, current.NewEnd = i, j
+			hunks = append(hunks, *current)
+			current = nil
+		}
+	}
+
+	i, j := 0, 0
+
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && oldLines[i] == newLines[j]:
+			flush(i, j)
+
+			i++
+			j++
+
+			continue
+
+		case current == nil:
+			current = &hunk{OldStart: i, NewStart: j}
+		}
+
+		if j >= m || (i < n && lcs[i+1][j] >= lcs[i][j+1]) {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	flush(i, j)
+
+	return hunks
+}
+
+// ApplyEdits applies the edits to the content of a file.
+// The duplicated edits are applied once, and an edit that overlaps a previous edit is an error.
+func ApplyEdits(raw []byte, edits []TextEdit) ([]byte, error) {
+	sorted := slices.Clone(edits)
+	slices.SortStableFunc(sorted, func(a, b TextEdit) int {
+		return cmp.Or(cmp.Compare(a.Start, b.Start), cmp.Compare(a.End, b.End))
+	})
+
+	sorted = slices.CompactFunc(sorted, func(a, b TextEdit) bool {
+		return a.Start == b.Start && a.End == b.End && bytes.Equal(a.NewText, b.NewText)
+	})
+
+	var (
+		b    bytes.Buffer
+		last int
+	)
+
+	for _, edit := range sorted {
+		if edit.Start < last || edit.End < edit.Start || edit.End > len(raw) {
+			return nil, fmt.Errorf("invalid or overlapping edit [%d, %d)", edit.Start, edit.End)
+		}
+
+		b.Write(raw[last:edit.Start])
+		b.Write(edit.NewText)
+
+		last = edit.End
+	}
+
+	b.Write(raw[last:])
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,160 @@
+package gomoddirectives
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+
+const unformattedGoMod = `module example.com/foo
+
+go   1.22
+
+require (
+    github.com/a/b v1.0.0
+	github.com/c/d v1.2.0 // indirect
+)
+require github.com/e/f v1.0.0
+
+
+`
+
+const formattedGoMod = `module example.com/foo
+
+go 1.22
+
+require (
+	github.com/a/b v1.0.0
+	github.com/c/d v1.2.0 // indirect
+)
+
+require github.com/e/f v1.0.0
+`
+
+func TestAnalyzeFile_format(t *testing.T) {
+	file := writeGoMod(t, t.TempDir(), unformattedGoMod)
+
+	results := AnalyzeFile(file, Options{ReplaceAllowAll: true, CheckFormat: true})
+
+	filename := file.Syntax.Name
+
+	expected := []Result{
+		{
+			Reason: "the file is not formatted canonically:\n@@ -3,1 +3,1 @@\n-go   1.22\n+go 1.22",
+			Start:  token.Position{Filename: filename, Line: 3, Column: 1},
+			End:    token.Position{Filename: filename, Line: 3, Column: 10},
+			Edits:  []TextEdit{{Start: 24, End: 34, NewText: []byte("go 1.22\n")}},
+		},
+		{
+			Reason: "the file is not formatted canonically:\n@@ -6,1 +6,1 @@\n-    github.com/a/b v1.0.0\n+\tgithub.com/a/b v1.0.0",
+			Start:  token.Position{Filename: filename, Line: 6, Column: 1},
+			End:    token.Position{Filename: filename, Line: 6, Column: 26},
+			Edits:  []TextEdit{{Start: 45, End: 71, NewText: []byte("\tgithub.com/a/b v1.0.0\n")}},
+		},
+		{
+			Reason: "the file is not formatted canonically:\n@@ -9,1 +9,0 @@\n-require github.com/e/f v1.0.0",
+			Start:  token.Position{Filename: filename, Line: 9, Column: 1},
+			End:    token.Position{Filename: filename, Line: 9, Column: 30},
+			Edits:  []TextEdit{{Start: 108, End: 138, NewText: []byte{}}},
+		},
+		{
+			Reason: "the file is not formatted canonically:\n@@ -11,1 +10,1 @@\n-\n+require github.com/e/f v1.0.0",
+			Start:  token.Position{Filename: filename, Line: 11, Column: 1},
+			End:    token.Position{Filename: filename, Line: 11, Column: 1},
+			Edits:  []TextEdit{{Start: 139, End: 140, NewText: []byte("require github.com/e/f v1.0.0\n")}},
+		},
+	}
+
+	assert.Equal(t, expected, results)
+
+	var edits []TextEdit
+	for _, result := range results {
+		edits = append(edits, result.Edits...)
+	}
+
+	fixed, err := ApplyEdits([]byte(unformattedGoMod), edits)
+	require.NoError(t, err)
+
+	assert.Equal(t, formattedGoMod, string(fixed))
+}
+
+func TestAnalyzeFile_format_canonical(t *testing.T) {
+	file := writeGoMod(t, t.TempDir(), formattedGoMod)
+
+	results := AnalyzeFile(file, Options{ReplaceAllowAll: true, CheckFormat: true})
+
+	assert.Empty(t, results)
+}
+
+func TestAnalyzeFile_format_content(t *testing.T) {
+	// the file doesn't exist on the disk: the content is provided by the options.
+	filename := filepath.Join(t.TempDir(), "go.mod")
+
+	file, err := modfile.Parse(filename, []byte(unformattedGoMod), nil)
+	require.NoError(t, err)
+
+	results := AnalyzeFile(file, Options{ReplaceAllowAll: true, CheckFormat: true}.WithContent([]byte(unformattedGoMod)))
+
+	require.Len(t, results, 4)
+
+	assert.Equal(t, filename, results[0].Start.Filename)
+}
+
+func TestApplyEdits(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		edits    []TextEdit
+		expected string
+	}{
+		{
+			desc:     "no edits",
+			expected: "abcdef",
+		},
+		{
+			desc: "unordered edits",
+			edits: []TextEdit{
+				{Start: 4, End: 5, NewText: []byte("E")},
+				{Start: 0, End: 1, NewText: []byte("A")},
+			},
+			expected: "AbcdEf",
+		},
+		{
+			desc: "duplicated edits",
+			edits: []TextEdit{
+				{Start: 2, End: 3, NewText: []byte("C")},
+				{Start: 2, End: 3, NewText: []byte("C")},
+			},
+			expected: "abCdef",
+		},
+		{
+			desc: "insertion",
+			edits: []TextEdit{
+				{Start: 6, End: 6, NewText: []byte("g")},
+			},
+			expected: "abcdefg",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			fixed, err := ApplyEdits([]byte("abcdef"), test.edits)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(fixed))
+		})
+	}
+}
+
+func TestApplyEdits_overlap(t *testing.T) {
+	_, err := ApplyEdits([]byte("abcdef"), []TextEdit{
+		{Start: 0, End: 3, NewText: []byte("A")},
+		{Start: 2, End: 4, NewText: []byte("B")},
+	})
+	require.Error(t, err)
+}
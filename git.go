@@ -0,0 +1,384 @@
+package gomoddirectives
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// gitRepository a local git repository.
+type gitRepository struct {
+	// WorkTree the root directory of the working tree.
+	WorkTree string
+	// GitDir the directory that contains the repository data (refs, objects, config).
+	GitDir string
+}
+
+// findGitRepository finds the git repository that contains a directory.
+// Returns nil if the directory is not inside a git repository.
+func findGitRepository(dir string) (*gitRepository, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		candidate := filepath.Join(dir, ".git")
+
+		info, err := os.Stat(candidate)
+		if err == nil {
+			gitDir := candidate
+
+			if !info.IsDir() {
+				// worktrees and submodules: the .git file contains the path to the git directory.
+				gitDir, err = readGitDirLink(candidate)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return &gitRepository{WorkTree: dir, GitDir: commonGitDir(gitDir)}, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+
+		dir = parent
+	}
+}
+
+func readGitDirLink(filename string) (string, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(raw)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("invalid git link: %s", filename)
+	}
+
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(filename), gitDir)
+	}
+
+	return gitDir, nil
+}
+
+// commonGitDir returns the directory that contains the refs shared by all the worktrees.
+func commonGitDir(gitDir string) string {
+	raw, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+
+	common := strings.TrimSpace(string(raw))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(gitDir, common)
+	}
+
+	return common
+}
+
+// Tags returns the names of the tags (without the "refs/tags/" prefix).
+func (r *gitRepository) Tags() ([]string, error) {
+	uniq := map[string]struct{}{}
+
+	tagsDir := filepath.Join(r.GitDir, "refs", "tags")
+
+	err := filepath.WalkDir(tagsDir, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(tagsDir, current)
+		if err != nil {
+			return err
+		}
+
+		uniq[filepath.ToSlash(rel)] = struct{}{}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read tags: %w", err)
+	}
+
+	packed, err := r.packedRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	for ref := range packed {
+		if name, ok := strings.CutPrefix(ref, "refs/tags/"); ok {
+			uniq[name] = struct{}{}
+		}
+	}
+
+	tags := make([]string, 0, len(uniq))
+	for name := range uniq {
+		tags = append(tags, name)
+	}
+
+	return tags, nil
+}
+
+// packedRefs reads the packed-refs file: reference name -> object ID.
+func (r *gitRepository) packedRefs() (map[string]string, error) {
+	refs := map[string]string{}
+
+	f, err := os.Open(filepath.Join(r.GitDir, "packed-refs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return refs, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// comments and peeled tags.
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+
+		id, name, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+
+		refs[name] = id
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read packed-refs: %w", err)
+	}
+
+	return refs, nil
+}
+
+// RemoteURL returns the URL of a remote from the git configuration.
+// Returns an empty string if the remote is not defined.
+func (r *gitRepository) RemoteURL(name string) (string, error) {
+	f, err := os.Open(filepath.Join(r.GitDir, "config"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	section := fmt.Sprintf("[remote %q]", name)
+
+	var inSection bool
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			inSection = line == section
+			continue
+		}
+
+		if !inSection {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "url") {
+			return strings.Trim(strings.TrimSpace(value), `"`), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("read git config: %w", err)
+	}
+
+	return "", nil
+}
+
+// remoteModulePath converts the URL of a remote to a module path prefix.
+//
+// Supported forms:
+//   - https://github.com/org/repo.git
+//   - ssh://git@github.com:22/org/repo.git
+//   - git@github.com:org/repo.git
+func remoteModulePath(rawURL string) (string, error) {
+	var host, p string
+
+	if u, err := url.Parse(rawURL); err == nil && u.Scheme != "" && u.Host != "" {
+		host, p = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		before, after, ok := strings.Cut(rawURL, ":")
+		if !ok || strings.Contains(before, "/") {
+			return "", fmt.Errorf("unsupported remote URL: %s", rawURL)
+		}
+
+		_, host, _ = strings.Cut(before, "@")
+		if host == "" {
+			host = before
+		}
+
+		p = after
+	}
+
+	p = strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+	if host == "" || p == "" {
+		return "", fmt.Errorf("unsupported remote URL: %s", rawURL)
+	}
+
+	return strings.ToLower(host) + "/" + p, nil
+}
+
+// moduleTagPrefix returns the prefix of the tags of a module located inside a repository.
+// https://go.dev/ref/mod#vcs-version
+func moduleTagPrefix(workTree, modDir, modPath string) (string, error) {
+	absDir, err := filepath.Abs(modDir)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(workTree, absDir)
+	if err != nil {
+		return "", err
+	}
+
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		return "", nil
+	}
+
+	// A module in a major version subdirectory uses the tags of the parent directory.
+	_, pathMajor, ok := module.SplitPathVersion(modPath)
+	if ok && pathMajor != "" && path.Base(rel) == strings.TrimPrefix(pathMajor, "/") {
+		rel = path.Dir(rel)
+		if rel == "." {
+			return "", nil
+		}
+	}
+
+	return rel + "/", nil
+}
+
+// expectedModulePath computes the module path from the "origin" remote of the local git repository
+// and the location of the module inside the repository.
+// Returns an empty string if the module is not inside a git repository, or if there is no "origin" remote.
+func expectedModulePath(modDir string) (string, error) {
+	repo, err := findGitRepository(modDir)
+	if err != nil || repo == nil {
+		return "", err
+	}
+
+	remote, err := repo.RemoteURL("origin")
+	if err != nil || remote == "" {
+		return "", err
+	}
+
+	expected, err := remoteModulePath(remote)
+	if err != nil {
+		return "", err
+	}
+
+	absDir, err := filepath.Abs(modDir)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(repo.WorkTree, absDir)
+	if err != nil {
+		return "", err
+	}
+
+	if rel == "." {
+		return expected, nil
+	}
+
+	return expected + "/" + filepath.ToSlash(rel), nil
+}
+
+// moduleVersions returns the versions of a module from the tags of the local git repository.
+// The versions of a major version sub-directory module (ex: v2/go.mod) are excluded.
+// Returns nil if the module is not inside a git repository.
+func moduleVersions(modDir, modPath string) ([]string, error) {
+	repo, err := findGitRepository(modDir)
+	if err != nil || repo == nil {
+		return nil, err
+	}
+
+	prefix, err := moduleTagPrefix(repo.WorkTree, modDir, modPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+
+	// the tags of a major version N belong to the module of the vN sub-directory, if it exists.
+	majorDirs := map[string]bool{}
+
+	for _, tag := range tags {
+		v, ok := strings.CutPrefix(tag, prefix)
+		if !ok || !semver.IsValid(v) || strings.Contains(v, "/") {
+			continue
+		}
+
+		major := semver.Major(v)
+		if major != "v0" && major != "v1" {
+			if _, ok := majorDirs[major]; !ok {
+				majorDirs[major] = isModuleRoot(filepath.Join(modDir, major))
+			}
+
+			if majorDirs[major] {
+				continue
+			}
+		}
+
+		versions = append(versions, v)
+	}
+
+	semver.Sort(versions)
+
+	return versions, nil
+}
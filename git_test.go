@@ -0,0 +1,398 @@
+package gomoddirectives
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+
+const fakeObjectID = "0123456789abcdef0123456789abcdef01234567"
+
+// setupGitRepository creates a minimal git directory with the given tags.
+// The first tags are stored as loose references, the others inside the packed-refs file.
+func setupGitRepository(t *testing.T, looseTags, packedTags []string) string {
+	t.Helper()
+
+	workTree := t.TempDir()
+
+	for _, tag := range looseTags {
+		filename := filepath.Join(workTree, ".git", "refs", "tags", filepath.FromSlash(tag))
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o750))
+		require.NoError(t, os.WriteFile(filename, []byte(fakeObjectID+"\n"), 0o600))
+	}
+
+	packed := []string{"# pack-refs with: peeled fully-peeled sorted"}
+	for _, tag := range packedTags {
+		packed = append(packed, fakeObjectID+" refs/tags/"+tag, "^"+fakeObjectID)
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(workTree, ".git", "refs", "heads"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(workTree, ".git", "packed-refs"), []byte(strings.Join(packed, "\n")+"\n"), 0o600))
+
+	return workTree
+}
+
+// writeGitConfig writes the git configuration with an "origin" remote.
+func writeGitConfig(t *testing.T, workTree, remote string) {
+	t.Helper()
+
+	config := `[core]
+	repositoryformatversion = 0
+	bare = false
+[remote "upstream"]
+	url = https://github.com/other/repo.git
+[remote "origin"]
+	url = ` + remote + `
+	fetch = +refs/heads/*:refs/remotes/origin/*
+`
+
+	require.NoError(t, os.WriteFile(filepath.Join(workTree, ".git", "config"), []byte(config), 0o600))
+}
+
+// writeGoMod writes a go.mod file inside a directory and parses it.
+func writeGoMod(t *testing.T, dir, content string) *modfile.File {
+	t.Helper()
+
+	filename := filepath.Join(dir, "go.mod")
+
+	require.NoError(t, os.MkdirAll(dir, 0o750))
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+
+	file, err := modfile.Parse(filename, []byte(content), nil)
+	require.NoError(t, err)
+
+	return file
+}
+
+func Test_findGitRepository(t *testing.T) {
+	workTree := setupGitRepository(t, nil, nil)
+
+	sub := filepath.Join(workTree, "a", "b")
+	require.NoError(t, os.MkdirAll(sub, 0o750))
+
+	repo, err := findGitRepository(sub)
+	require.NoError(t, err)
+	require.NotNil(t, repo)
+
+	assert.Equal(t, workTree, repo.WorkTree)
+	assert.Equal(t, filepath.Join(workTree, ".git"), repo.GitDir)
+}
+
+func Test_findGitRepository_worktree(t *testing.T) {
+	main := setupGitRepository(t, nil, nil)
+
+	gitDir := filepath.Join(main, ".git", "worktrees", "wt")
+	require.NoError(t, os.MkdirAll(gitDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0o600))
+
+	workTree := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workTree, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o600))
+
+	repo, err := findGitRepository(workTree)
+	require.NoError(t, err)
+	require.NotNil(t, repo)
+
+	assert.Equal(t, workTree, repo.WorkTree)
+	assert.Equal(t, filepath.Join(main, ".git"), repo.GitDir)
+}
+
+func Test_moduleVersions(t *testing.T) {
+	workTree := setupGitRepository(t,
+		[]string{"v1.0.0", "v1.2.0", "sub/v0.1.0", "not-a-version"},
+		[]string{"v1.1.0", "sub/v0.2.0", "sub/v2.0.0", "sub/deep/v1.0.0"},
+	)
+
+	testCases := []struct {
+		desc     string
+		dir      string
+		modPath  string
+		expected []string
+	}{
+		{
+			desc:     "root module",
+			dir:      workTree,
+			modPath:  "example.com/foo",
+			expected: []string{"v1.0.0", "v1.1.0", "v1.2.0"},
+		},
+		{
+			desc:     "module in a sub-directory",
+			dir:      filepath.Join(workTree, "sub"),
+			modPath:  "example.com/foo/sub",
+			expected: []string{"v0.1.0", "v0.2.0", "v2.0.0"},
+		},
+		{
+			desc:     "module in a major version sub-directory",
+			dir:      filepath.Join(workTree, "sub", "v2"),
+			modPath:  "example.com/foo/sub/v2",
+			expected: []string{"v0.1.0", "v0.2.0", "v2.0.0"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			versions, err := moduleVersions(test.dir, test.modPath)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, versions)
+		})
+	}
+}
+
+func Test_moduleVersions_majorSubdirectory(t *testing.T) {
+	workTree := setupGitRepository(t, []string{"v1.5.0", "v2.0.0"}, nil)
+
+	writeGoMod(t, workTree, "module github.com/o/r\n")
+	writeGoMod(t, filepath.Join(workTree, "v2"), "module github.com/o/r/v2\n")
+
+	versions, err := moduleVersions(workTree, "github.com/o/r")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v1.5.0"}, versions)
+
+	versions, err = moduleVersions(filepath.Join(workTree, "v2"), "github.com/o/r/v2")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v1.5.0", "v2.0.0"}, versions)
+}
+
+func Test_moduleVersions_noRepository(t *testing.T) {
+	versions, err := moduleVersions(t.TempDir(), "example.com/foo")
+	require.NoError(t, err)
+
+	assert.Empty(t, versions)
+}
+
+func TestAnalyzeFile_retractCurrentRelease(t *testing.T) {
+	workTree := setupGitRepository(t, []string{"v1.0.0", "v1.1.0", "v2.0.0"}, nil)
+
+	file := writeGoMod(t, workTree, `module example.com/foo
+
+go 1.22
+
+retract (
+	v1.0.0 // broken
+	v1.1.0 // broken
+)
+`)
+
+	results := AnalyzeFile(file, Options{RetractCheckRelease: true})
+
+	require.Len(t, results, 1)
+
+	assert.Equal(t, "the current release of the module (v1.1.0) is retracted", results[0].Reason)
+	assert.Equal(t, 7, results[0].Start.Line)
+}
+
+func TestAnalyzeFile_retractTags(t *testing.T) {
+	workTree := setupGitRepository(t, []string{"sub/v1.2.3"}, []string{"v1.3.2", "sub/v1.4.0", "sub/v2.0.0"})
+
+	file := writeGoMod(t, filepath.Join(workTree, "sub"), `module example.com/foo/sub
+
+go 1.22
+
+retract (
+	v1.2.3 // broken
+	v1.3.2 // typo: not a tag of this module
+	[v1.3.5, v1.4.5] // broken
+	[v1.5.0, v1.9.0] // nothing tagged
+)
+`)
+
+	results := AnalyzeFile(file, Options{RetractCheckTags: true})
+
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "the retracted versions don't match any tag of the local git repository", results[0].Reason)
+	assert.Equal(t, 7, results[0].Start.Line)
+	assert.Equal(t, "the retracted versions don't match any tag of the local git repository", results[1].Reason)
+	assert.Equal(t, 9, results[1].Start.Line)
+}
+
+func TestAnalyzeFile_retractTags_noTags(t *testing.T) {
+	workTree := setupGitRepository(t, nil, nil)
+
+	file := writeGoMod(t, workTree, `module example.com/foo
+
+go 1.22
+
+retract v1.0.0 // broken
+`)
+
+	results := AnalyzeFile(file, Options{RetractCheckTags: true, RetractCheckRelease: true})
+
+	assert.Empty(t, results)
+}
+
+func Test_remoteModulePath(t *testing.T) {
+	testCases := []struct {
+		remote   string
+		expected string
+	}{
+		{remote: "https://github.com/ourorg/repo.git", expected: "github.com/ourorg/repo"},
+		{remote: "https://github.com/ourorg/repo", expected: "github.com/ourorg/repo"},
+		{remote: "https://user@GitHub.com/ourorg/repo/", expected: "github.com/ourorg/repo"},
+		{remote: "ssh://git@github.com:22/ourorg/repo.git", expected: "github.com/ourorg/repo"},
+		{remote: "git@github.com:ourorg/repo.git", expected: "github.com/ourorg/repo"},
+		{remote: "go.ourcorp.internal:team/repo", expected: "go.ourcorp.internal/team/repo"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.remote, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := remoteModulePath(test.remote)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, p)
+		})
+	}
+}
+
+func Test_remoteModulePath_error(t *testing.T) {
+	_, err := remoteModulePath("/srv/git/repo.git")
+	require.Error(t, err)
+}
+
+func TestAnalyzeFile_modulePathVCS(t *testing.T) {
+	workTree := setupGitRepository(t, nil, nil)
+	writeGitConfig(t, workTree, "git@github.com:ourorg/repo.git")
+
+	testCases := []struct {
+		desc     string
+		dir      string
+		content  string
+		expected []string
+	}{
+		{
+			desc:    "root module",
+			dir:     "",
+			content: "module github.com/ourorg/repo\n",
+		},
+		{
+			desc:    "root module with major version",
+			dir:     "",
+			content: "module github.com/ourorg/repo/v3\n",
+		},
+		{
+			desc:    "module in a sub-directory",
+			dir:     "sub",
+			content: "module github.com/ourorg/repo/sub\n",
+		},
+		{
+			desc:     "invalid module in a sub-directory",
+			dir:      "invalid",
+			content:  "module github.com/ourorg/repo/other\n",
+			expected: []string{"module path (github.com/ourorg/repo/other) doesn't match the repository (expected: github.com/ourorg/repo/invalid)"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := modfile.Parse(filepath.Join(workTree, test.dir, "go.mod"), []byte(test.content), nil)
+			require.NoError(t, err)
+
+			results := AnalyzeFile(file, Options{ModulePathCheckVCS: true})
+
+			var reasons []string
+			for _, result := range results {
+				reasons = append(reasons, result.Reason)
+			}
+
+			assert.Equal(t, test.expected, reasons)
+		})
+	}
+}
+
+func TestAnalyzeFile_modulePathMajor(t *testing.T) {
+	workTree := setupGitRepository(t,
+		[]string{"v1.0.0", "v2.0.0", "v2.1.0", "a/v1.5.0", "b/v0.1.0", "b/v1.0.0"},
+		[]string{"yaml/v3.0.1", "c/v2.0.0"},
+	)
+
+	testCases := []struct {
+		desc     string
+		dir      string
+		content  string
+		expected []string
+	}{
+		{
+			desc:    "major version suffix",
+			content: "module example.com/foo/v2\n",
+		},
+		{
+			desc:     "missing major version suffix",
+			content:  "module example.com/foo\n",
+			expected: []string{"module path (example.com/foo) doesn't match the major version of the latest tag (v2.1.0)"},
+		},
+		{
+			desc:     "unexpected major version suffix",
+			dir:      "a",
+			content:  "module example.com/foo/a/v2\n",
+			expected: []string{"module path (example.com/foo/a/v2) doesn't match the major version of the latest tag (v1.5.0)"},
+		},
+		{
+			desc:    "v1 module",
+			dir:     "b",
+			content: "module example.com/foo/b\n",
+		},
+		{
+			desc:    "major version sub-directory",
+			dir:     filepath.Join("c", "v2"),
+			content: "module example.com/foo/c/v2\n",
+		},
+		{
+			desc:    "gopkg.in",
+			dir:     "yaml",
+			content: "module gopkg.in/yaml.v3\n",
+		},
+		{
+			desc:     "gopkg.in mismatch",
+			dir:      "yaml",
+			content:  "module gopkg.in/yaml.v2\n",
+			expected: []string{"module path (gopkg.in/yaml.v2) doesn't match the major version of the latest tag (v3.0.1)"},
+		},
+		{
+			desc:    "no tags",
+			dir:     "d",
+			content: "module example.com/foo/d/v5\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := modfile.Parse(filepath.Join(workTree, test.dir, "go.mod"), []byte(test.content), nil)
+			require.NoError(t, err)
+
+			results := AnalyzeFile(file, Options{ModulePathCheckMajor: true})
+
+			var reasons []string
+			for _, result := range results {
+				reasons = append(reasons, result.Reason)
+			}
+
+			assert.Equal(t, test.expected, reasons)
+		})
+	}
+}
+
+func TestAnalyzeFile_modulePathMajor_subdirectory(t *testing.T) {
+	workTree := setupGitRepository(t, []string{"v1.5.0", "v2.0.0"}, nil)
+
+	root := writeGoMod(t, workTree, "module github.com/o/r\n")
+	v2 := writeGoMod(t, filepath.Join(workTree, "v2"), "module github.com/o/r/v2\n")
+
+	assert.Empty(t, AnalyzeFile(root, Options{ModulePathCheckMajor: true}))
+	assert.Empty(t, AnalyzeFile(v2, Options{ModulePathCheckMajor: true}))
+}
@@ -5,36 +5,126 @@ import (
 	"context"
 	"fmt"
 	"go/token"
+	"go/version"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
 
+	"github.com/ldez/grignotin/goenv"
 	"github.com/ldez/grignotin/gomod"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 	"golang.org/x/tools/go/analysis"
 )
 
 const (
-	reasonExclude          = "exclude directive is not allowed"
-	reasonGoDebug          = "godebug directive is not allowed"
-	reasonGoVersion        = "go directive (%s) doesn't match the pattern '%s'"
-	reasonIgnore           = "ignore directive is not allowed"
-	reasonReplace          = "replacement are not allowed"
-	reasonReplaceDuplicate = "multiple replacement of the same module"
-	reasonReplaceIdentical = "the original module and the replacement are identical"
-	reasonReplaceLocal     = "local replacement are not allowed"
-	reasonRetract          = "a comment is mandatory to explain why the version has been retracted"
-	reasonTool             = "tool directive is not allowed"
-	reasonToolchain        = "toolchain directive is not allowed"
-	reasonToolchainPattern = "toolchain directive (%s) doesn't match the pattern '%s'"
+	reasonChangeDowngrade      = "the dependency %s is downgraded from %s to %s"
+	reasonChangeGoVersion      = "go directive is lowered from %s to %s"
+	reasonChangeMajor          = "major upgrade of %s (%s => %s) must be reviewed"
+	reasonChangeReplace        = "new replace directive not in the allow list: %s"
+	reasonDeprecated           = "module deprecation is not allowed"
+	reasonDeprecatedFormat     = "deprecation message (%s) doesn't match the pattern '%s'"
+	reasonDeprecatedModule     = "invalid replacement module in the deprecation message: %v"
+	reasonExclude              = "exclude directive is not allowed"
+	reasonExcludeDuplicate     = "multiple exclusions of the same module version"
+	reasonExcludeRequire       = "the excluded module is not required"
+	reasonExcludeRequired      = "the excluded version is the required version"
+	reasonFormat               = "the file is not formatted canonically"
+	reasonGoDebug              = "godebug directive is not allowed"
+	reasonGoSumConflict        = "go.sum contains conflicting hashes for %s"
+	reasonGoSumLocal           = "go.sum contains an entry for %s, but the module is replaced by a local directory"
+	reasonGoSumMalformed       = "malformed go.sum line"
+	reasonGoSumMissing         = "missing go.sum entry for the go.mod file of %s"
+	reasonGoSumStale           = "go.sum contains an entry for %s, but the module is not in the module graph"
+	reasonGoVersion            = "go directive (%s) doesn't match the pattern '%s'"
+	reasonGoVersionDep         = "go directive (%s) is lower than the go directive of the dependency %s (%s)"
+	reasonIgnore               = "ignore directive is not allowed"
+	reasonIgnoreGoPackages     = "the ignored path contains Go packages of the module"
+	reasonIgnoreMissing        = "the ignored path doesn't exist"
+	reasonLayoutOrder          = "%s directive should be placed before the %s directive"
+	reasonLayoutReplaceBlock   = "replace directives should be grouped in a single block"
+	reasonLayoutSorted         = "%s is not sorted in the require block"
+	reasonModulePathMajor      = "module path (%s) doesn't match the major version of the latest tag (%s)"
+	reasonModulePathPrefix     = "module path (%s) doesn't match the allowed prefixes: %s"
+	reasonModulePathVCS        = "module path (%s) doesn't match the repository (expected: %s)"
+	reasonNotInCache           = "the module is not in the module cache, skipped"
+	reasonPolicy               = "%s directive is forbidden by the policy %q"
+	reasonReplace              = "replacement are not allowed"
+	reasonReplaceDuplicate     = "multiple replacement of the same module"
+	reasonReplaceIdentical     = "the original module and the replacement are identical"
+	reasonReplaceLocal         = "local replacement are not allowed"
+	reasonRequireDeprecated    = "the required module %s is deprecated: %s"
+	reasonRequireDirectBlock   = "the direct requirement %s is in a block of indirect requirements"
+	reasonRequireDuplicate     = "multiple requirements of %s with different versions (%s, %s)"
+	reasonRequireIndirectBlock = "the indirect requirement %s is in a block of direct requirements"
+	reasonRequireRetracted     = "the required version %s is retracted by the module (latest: %s): %s"
+	reasonRetract              = "a comment is mandatory to explain why the version has been retracted"
+	reasonRetractCurrent       = "the current release of the module (%s) is retracted"
+	reasonRetractDuplicate     = "multiple retractions of the same versions"
+	reasonRetractOverlap       = "the retracted versions overlap with another retract directive"
+	reasonRetractPattern       = "retract rationale (%s) doesn't match the pattern '%s'"
+	reasonRetractRange         = "invalid retract range: the low version (%s) is greater than the high version (%s)"
+	reasonRetractTag           = "the retracted versions don't match any tag of the local git repository"
+	reasonTool                 = "tool directive is not allowed"
+	reasonToolDuplicate        = "multiple tool directives for the same package"
+	reasonToolRequire          = "the tool is not provided by a required module or the main module"
+	reasonToolchain            = "toolchain directive is not allowed"
+	reasonToolchainDep         = "toolchain directive (%s) is lower than the toolchain directive of the dependency %s (%s)"
+	reasonToolchainPattern     = "toolchain directive (%s) doesn't match the pattern '%s'"
+	reasonVendorExplicit       = "%s is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt"
+	reasonVendorGoVersion      = "%s is marked with go version '%s' in vendor/modules.txt, but its go.mod file has go version '%s'"
+	reasonVendorNotReplaced    = "%s is replaced in go.mod, but not marked as replaced in vendor/modules.txt"
+	reasonVendorNotRequired    = "%s is marked as explicit in vendor/modules.txt, but not explicitly required in go.mod"
+	reasonVendorReplaced       = "%s is marked as replaced in vendor/modules.txt, but not replaced in go.mod"
+	reasonVendorReplacement    = "%s is replaced by %s in go.mod, but marked as replaced by %s in vendor/modules.txt"
+	reasonVendorVersion        = "%s is required at version %s in go.mod, but vendor/modules.txt has version %s"
 )
 
+// Severity the severity of a result.
+type Severity int
+
+// Severities.
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// ParseSeverity parses the name of a severity (error, warning, info).
+func ParseSeverity(s string) (Severity, error) {
+	switch s {
+	case "error", "":
+		return SeverityError, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "info":
+		return SeverityInfo, nil
+	default:
+		return SeverityError, fmt.Errorf("unknown severity: %q", s)
+	}
+}
+
 // Result the analysis result.
 type Result struct {
-	Reason string
-	Start  token.Position
-	End    token.Position
+	Reason   string
+	Start    token.Position
+	End      token.Position
+	Severity Severity
+	// Edits the edits to apply to the file to fix the problem (optional).
+	Edits []TextEdit
 }
 
 // NewResult creates a new Result.
@@ -47,7 +137,11 @@ func NewResult(file *modfile.File, line *modfile.Line, reason string) Result {
 }
 
 func (r Result) String() string {
-	return fmt.Sprintf("%s: %s", r.Start, r.Reason)
+	if r.Severity == SeverityError {
+		return fmt.Sprintf("%s: %s", r.Start, r.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", r.Start, r.Severity, r.Reason)
 }
 
 // Options the analyzer options.
@@ -56,21 +150,94 @@ type Options struct {
 	ReplaceAllowList          []string
 	ReplaceAllowLocal         bool
 	ExcludeForbidden          bool
+	ExcludeAllowList          []string
+	ExcludeCheckRequire       bool
 	IgnoreForbidden           bool
+	IgnoreAllowList           []string
+	IgnoreCheckPaths          bool
 	RetractAllowNoExplanation bool
+	RetractRationalePattern   *regexp.Regexp
+	RetractCheckRanges        bool
+	RetractCheckRelease       bool
+	RetractCheckTags          bool
 	ToolchainForbidden        bool
 	ToolchainPattern          *regexp.Regexp
 	ToolForbidden             bool
+	ToolAllowList             []string
+	ToolCheckRequire          bool
 	GoDebugForbidden          bool
 	GoVersionPattern          *regexp.Regexp
 	CheckModulePath           bool
+	ModulePathPrefixes        []string
+	ModulePathCheckVCS        bool
+	ModulePathCheckMajor      bool
+	DeprecatedForbidden       bool
+	DeprecatedPattern         *regexp.Regexp
+	RequireCheckDeprecated    bool
+	RequireCheckRetracted     bool
+	RequireCheckGoVersion     bool
+	RequireCheckBlocks        bool
+	CheckGoSum                bool
+	CheckVendor               bool
+	CheckFormat               bool
+	CheckLayout               bool
+	LayoutOrder               []string
+	PureGoDiscovery           bool
+	// Policies the declarative rules (see [Policy]).
+	Policies []Policy
+	// RuleSettings the settings of the rules, by rule ID (see [DecodeRuleSettings]).
+	RuleSettings map[string]any
+
+	// moduleDir the directory of the module (the directory of the module file if empty).
+	moduleDir string
+	// content the content of the analyzed file (read from the file if nil).
+	content []byte
+}
+
+// WithModuleDir returns a copy of the options where the directory of the module is the given directory.
+// The paths of the directives (ex: ignored paths) are resolved from this directory,
+// instead of the directory of the module file (ex: the module file is parsed with a relative name).
+func (o Options) WithModuleDir(dir string) Options {
+	o.moduleDir = dir
+	return o
+}
+
+// WithContent returns a copy of the options where the content of the analyzed file is the given content,
+// instead of the content of the file on the disk (ex: the file is read from the standard input).
+func (o Options) WithContent(content []byte) Options {
+	o.content = content
+	return o
 }
 
 // AnalyzePass analyzes a pass.
 func AnalyzePass(pass *analysis.Pass, opts Options) ([]Result, error) {
-	info, err := gomod.GetModuleInfo(context.Background())
+	return AnalyzePassContext(context.Background(), pass, opts)
+}
+
+// AnalyzePassContext analyzes a pass.
+func AnalyzePassContext(ctx context.Context, pass *analysis.Pass, opts Options) ([]Result, error) {
+	goMod, err := passGoMod(ctx, pass, opts)
 	if err != nil {
-		return nil, fmt.Errorf("get information about modules: %w", err)
+		return nil, err
+	}
+
+	f, err := parseGoMod(goMod)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", goMod, err)
+	}
+
+	return AnalyzeFile(f, opts.WithModuleDir(filepath.Dir(goMod))), nil
+}
+
+// passGoMod returns the path of the go.mod file of the module of a pass.
+func passGoMod(ctx context.Context, pass *analysis.Pass, opts Options) (string, error) {
+	if opts.PureGoDiscovery {
+		return discoverPassGoMod(ctx, pass)
+	}
+
+	info, err := gomod.GetModuleInfo(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get information about modules: %w", err)
 	}
 
 	goMod := info[0].GoMod
@@ -84,56 +251,166 @@ func AnalyzePass(pass *analysis.Pass, opts Options) ([]Result, error) {
 		}
 	}
 
+	return goMod, nil
+}
+
+// Analyze analyzes a project.
+func Analyze(opts Options) ([]Result, error) {
+	return AnalyzeContext(context.Background(), opts)
+}
+
+// AnalyzeContext analyzes a project.
+func AnalyzeContext(ctx context.Context, opts Options) ([]Result, error) {
+	goMod, err := projectGoMod(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module file: %w", err)
+	}
+
 	f, err := parseGoMod(goMod)
 	if err != nil {
-		return nil, fmt.Errorf("parse %s: %w", goMod, err)
+		return nil, fmt.Errorf("failed to get module file: %w", err)
 	}
 
-	return AnalyzeFile(f, opts), nil
+	return AnalyzeFile(f, opts.WithModuleDir(filepath.Dir(goMod))), nil
 }
 
-// Analyze analyzes a project.
-func Analyze(opts Options) ([]Result, error) {
-	f, err := GetModuleFile()
+// projectGoMod returns the path of the go.mod file of the module of the current directory.
+func projectGoMod(ctx context.Context, opts Options) (string, error) {
+	if !opts.PureGoDiscovery {
+		return goenv.GetOne(ctx, goenv.GOMOD)
+	}
+
+	files, err := discoverModuleFiles(ctx, ".")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get module file: %w", err)
+		return "", err
 	}
 
-	return AnalyzeFile(f, opts), nil
+	return files.GoMod, nil
 }
 
 // AnalyzeFile analyzes a mod file.
+// The built-in rules and the registered rules ([RegisterRule]) are executed.
 func AnalyzeFile(file *modfile.File, opts Options) []Result {
-	checks := []func(file *modfile.File, opts Options) []Result{
-		checkModulePath,
-		checkRetractDirectives,
-		checkExcludeDirectives,
-		checkToolDirectives,
-		checkIgnoreDirectives,
-		checkReplaceDirectives,
-		checkToolchainDirective,
-		checkGoDebugDirectives,
-		checkGoVersionDirectives,
-	}
-
 	var results []Result
-	for _, check := range checks {
-		results = append(results, check(file, opts)...)
+	for _, rule := range Rules() {
+		results = append(results, rule.Check(file, opts)...)
 	}
 
 	return results
 }
 
 func checkModulePath(file *modfile.File, opts Options) []Result {
-	if file.Module == nil || !opts.CheckModulePath {
+	if file.Module == nil {
 		return nil
 	}
 
-	err := module.CheckPath(file.Module.Mod.Path)
+	modPath := file.Module.Mod.Path
+
+	if opts.CheckModulePath {
+		err := module.CheckPath(modPath)
+		if err != nil {
+			return []Result{NewResult(file, file.Module.Syntax, err.Error())}
+		}
+	}
+
+	var results []Result
+
+	if len(opts.ModulePathPrefixes) > 0 && !slices.ContainsFunc(opts.ModulePathPrefixes, func(prefix string) bool {
+		return hasPathPrefix(modPath, strings.TrimSuffix(prefix, "/"))
+	}) {
+		reason := fmt.Sprintf(reasonModulePathPrefix, modPath, strings.Join(opts.ModulePathPrefixes, ", "))
+		results = append(results, NewResult(file, file.Module.Syntax, reason))
+	}
+
+	if opts.ModulePathCheckVCS {
+		expected, err := expectedModulePath(moduleDir(file, opts))
+		if err != nil {
+			return append(results, NewResult(file, file.Module.Syntax, err.Error()))
+		}
+
+		if expected != "" && !matchExpectedModulePath(modPath, expected) {
+			results = append(results, NewResult(file, file.Module.Syntax, fmt.Sprintf(reasonModulePathVCS, modPath, expected)))
+		}
+	}
+
+	if opts.ModulePathCheckMajor {
+		results = append(results, checkModulePathMajor(file, opts)...)
+	}
+
+	return results
+}
+
+// checkModulePathMajor checks that the major version suffix of the module path (`/vN` or `.vN` for gopkg.in)
+// matches the major version of the latest tag of the module in the local git repository.
+func checkModulePathMajor(file *modfile.File, opts Options) []Result {
+	modPath := file.Module.Mod.Path
+
+	versions, err := moduleVersions(moduleDir(file, opts), modPath)
 	if err != nil {
 		return []Result{NewResult(file, file.Module.Syntax, err.Error())}
 	}
 
+	if len(versions) == 0 {
+		return nil
+	}
+
+	latest := versions[len(versions)-1]
+
+	_, pathMajor, ok := module.SplitPathVersion(modPath)
+	if !ok || module.CheckPathMajor(latest, pathMajor) == nil {
+		return nil
+	}
+
+	return []Result{NewResult(file, file.Module.Syntax, fmt.Sprintf(reasonModulePathMajor, modPath, latest))}
+}
+
+// matchExpectedModulePath checks if the module path is the expected path, with an optional major version suffix.
+func matchExpectedModulePath(modPath, expected string) bool {
+	if modPath == expected {
+		return true
+	}
+
+	prefix, pathMajor, ok := module.SplitPathVersion(modPath)
+
+	return ok && pathMajor != "" && prefix == expected
+}
+
+// checkModuleDeprecation checks the deprecation comment of the module (`// Deprecated: ...`).
+// When the pattern contains a sub-match named "module" (or any sub-match), the sub-match must be a valid module path.
+func checkModuleDeprecation(file *modfile.File, opts Options) []Result {
+	if file.Module == nil || file.Module.Deprecated == "" {
+		return nil
+	}
+
+	if opts.DeprecatedForbidden {
+		return []Result{NewResult(file, file.Module.Syntax, reasonDeprecated)}
+	}
+
+	if opts.DeprecatedPattern == nil {
+		return nil
+	}
+
+	msg := file.Module.Deprecated
+
+	match := opts.DeprecatedPattern.FindStringSubmatch(msg)
+	if match == nil {
+		return []Result{NewResult(file, file.Module.Syntax, fmt.Sprintf(reasonDeprecatedFormat, msg, opts.DeprecatedPattern.String()))}
+	}
+
+	idx := opts.DeprecatedPattern.SubexpIndex("module")
+	if idx < 0 && len(match) > 1 {
+		idx = 1
+	}
+
+	if idx < 0 {
+		return nil
+	}
+
+	err := module.CheckPath(match[idx])
+	if err != nil {
+		return []Result{NewResult(file, file.Module.Syntax, fmt.Sprintf(reasonDeprecatedModule, err))}
+	}
+
 	return nil
 }
 
@@ -166,65 +443,349 @@ func checkToolchainDirective(file *modfile.File, opts Options) []Result {
 }
 
 func checkRetractDirectives(file *modfile.File, opts Options) []Result {
-	if opts.RetractAllowNoExplanation {
-		return nil
-	}
-
 	var results []Result
 
+	var previous []*modfile.Retract
+
 	for _, retract := range file.Retract {
-		if retract.Rationale != "" {
+		if reason := checkRetractRationale(opts, retract); reason != "" {
+			results = append(results, NewResult(file, retract.Syntax, reason))
+		}
+
+		if !opts.RetractCheckRanges {
 			continue
 		}
 
-		results = append(results, NewResult(file, retract.Syntax, reasonRetract))
+		if semver.Compare(retract.Low, retract.High) > 0 {
+			results = append(results, NewResult(file, retract.Syntax, fmt.Sprintf(reasonRetractRange, retract.Low, retract.High)))
+			continue
+		}
+
+		for _, other := range previous {
+			if other.Low == retract.Low && other.High == retract.High {
+				results = append(results, NewResult(file, retract.Syntax, reasonRetractDuplicate))
+				break
+			}
+
+			if semver.Compare(retract.Low, other.High) <= 0 && semver.Compare(other.Low, retract.High) <= 0 {
+				results = append(results, NewResult(file, retract.Syntax, reasonRetractOverlap))
+				break
+			}
+		}
+
+		previous = append(previous, retract)
+	}
+
+	if opts.RetractCheckRelease || opts.RetractCheckTags {
+		results = append(results, checkRetractVersions(file, opts)...)
 	}
 
 	return results
 }
 
-func checkExcludeDirectives(file *modfile.File, opts Options) []Result {
-	if !opts.ExcludeForbidden {
+func checkRetractRationale(opts Options, retract *modfile.Retract) string {
+	if retract.Rationale == "" {
+		if opts.RetractAllowNoExplanation {
+			return ""
+		}
+
+		return reasonRetract
+	}
+
+	if opts.RetractRationalePattern == nil || opts.RetractRationalePattern.MatchString(retract.Rationale) {
+		return ""
+	}
+
+	return fmt.Sprintf(reasonRetractPattern, retract.Rationale, opts.RetractRationalePattern.String())
+}
+
+// checkRetractVersions checks the retracted versions against the tags of the local git repository.
+// The check is skipped when no tags are available (e.g. outside a git repository, shallow clone without tags).
+func checkRetractVersions(file *modfile.File, opts Options) []Result {
+	if file.Module == nil || len(file.Retract) == 0 {
 		return nil
 	}
 
+	versions, err := moduleVersions(moduleDir(file, opts), file.Module.Mod.Path)
+	if err != nil {
+		return []Result{NewResult(file, file.Module.Syntax, err.Error())}
+	}
+
+	_, pathMajor, _ := module.SplitPathVersion(file.Module.Mod.Path)
+
+	versions = slices.DeleteFunc(versions, func(v string) bool {
+		return !module.MatchPathMajor(v, pathMajor)
+	})
+
+	if len(versions) == 0 {
+		return nil
+	}
+
+	current := versions[len(versions)-1]
+
 	var results []Result
 
+	for _, retract := range file.Retract {
+		if opts.RetractCheckRelease && isRetracted(retract, current) {
+			results = append(results, NewResult(file, retract.Syntax, fmt.Sprintf(reasonRetractCurrent, current)))
+		}
+
+		if opts.RetractCheckTags && !slices.ContainsFunc(versions, func(v string) bool { return isRetracted(retract, v) }) {
+			results = append(results, NewResult(file, retract.Syntax, reasonRetractTag))
+		}
+	}
+
+	return results
+}
+
+// isRetracted checks if a version is inside a retracted interval.
+func isRetracted(retract *modfile.Retract, v string) bool {
+	return semver.Compare(retract.Low, v) <= 0 && semver.Compare(v, retract.High) <= 0
+}
+
+func checkExcludeDirectives(file *modfile.File, opts Options) []Result {
+	var results []Result
+
+	uniqExclude := map[module.Version]struct{}{}
+
 	for _, exclude := range file.Exclude {
-		results = append(results, NewResult(file, exclude.Syntax, reasonExclude))
+		if opts.ExcludeForbidden && !slices.Contains(opts.ExcludeAllowList, exclude.Mod.Path) {
+			results = append(results, NewResult(file, exclude.Syntax, reasonExclude))
+			continue
+		}
+
+		if !opts.ExcludeCheckRequire {
+			continue
+		}
+
+		if _, ok := uniqExclude[exclude.Mod]; ok {
+			results = append(results, NewResult(file, exclude.Syntax, reasonExcludeDuplicate))
+			continue
+		}
+
+		uniqExclude[exclude.Mod] = struct{}{}
+
+		if reason := checkExcludeRequire(file, exclude); reason != "" {
+			results = append(results, NewResult(file, exclude.Syntax, fmt.Sprintf("%s: %s", reason, exclude.Mod)))
+		}
 	}
 
 	return results
 }
 
-func checkIgnoreDirectives(file *modfile.File, opts Options) []Result {
-	if !opts.IgnoreForbidden {
-		return nil
+func checkExcludeRequire(file *modfile.File, exclude *modfile.Exclude) string {
+	idx := slices.IndexFunc(file.Require, func(r *modfile.Require) bool {
+		return r.Mod.Path == exclude.Mod.Path
+	})
+
+	switch {
+	case idx < 0:
+		return reasonExcludeRequire
+
+	case file.Require[idx].Mod.Version == exclude.Mod.Version:
+		// The exclusion forces a silent upgrade at build time.
+		return reasonExcludeRequired
+
+	default:
+		return ""
 	}
+}
 
+func checkIgnoreDirectives(file *modfile.File, opts Options) []Result {
 	var results []Result
 
-	for _, exclude := range file.Ignore {
-		results = append(results, NewResult(file, exclude.Syntax, reasonIgnore))
+	for _, ignore := range file.Ignore {
+		if opts.IgnoreForbidden && !isIgnoreAllowed(opts, ignore.Path) {
+			results = append(results, NewResult(file, ignore.Syntax, reasonIgnore))
+			continue
+		}
+
+		if !opts.IgnoreCheckPaths {
+			continue
+		}
+
+		reason, err := checkIgnoredPath(moduleDir(file, opts), ignore.Path)
+		if err != nil {
+			results = append(results, NewResult(file, ignore.Syntax, err.Error()))
+			continue
+		}
+
+		if reason != "" {
+			results = append(results, NewResult(file, ignore.Syntax, fmt.Sprintf("%s: %s", reason, ignore.Path)))
+		}
 	}
 
 	return results
 }
 
+func isIgnoreAllowed(opts Options, p string) bool {
+	return slices.ContainsFunc(opts.IgnoreAllowList, func(pattern string) bool {
+		return module.MatchPrefixPatterns(pattern, p)
+	})
+}
+
 func checkToolDirectives(file *modfile.File, opts Options) []Result {
-	if !opts.ToolForbidden {
+	var results []Result
+
+	uniqTool := map[string]struct{}{}
+
+	for _, tool := range file.Tool {
+		if opts.ToolForbidden && !isToolAllowed(opts, tool.Path) {
+			results = append(results, NewResult(file, tool.Syntax, reasonTool))
+			continue
+		}
+
+		if !opts.ToolCheckRequire {
+			continue
+		}
+
+		if _, ok := uniqTool[tool.Path]; ok {
+			results = append(results, NewResult(file, tool.Syntax, reasonToolDuplicate))
+			continue
+		}
+
+		uniqTool[tool.Path] = struct{}{}
+
+		if !isToolProvided(file, tool.Path) {
+			results = append(results, NewResult(file, tool.Syntax, fmt.Sprintf("%s: %s", reasonToolRequire, tool.Path)))
+		}
+	}
+
+	return results
+}
+
+func isToolAllowed(opts Options, pkg string) bool {
+	return slices.ContainsFunc(opts.ToolAllowList, func(pattern string) bool {
+		return module.MatchPrefixPatterns(pattern, pkg)
+	})
+}
+
+// isToolProvided checks if the package of a tool belongs to the main module or to a required module.
+func isToolProvided(file *modfile.File, pkg string) bool {
+	if file.Module != nil && hasPathPrefix(pkg, file.Module.Mod.Path) {
+		return true
+	}
+
+	return slices.ContainsFunc(file.Require, func(r *modfile.Require) bool {
+		return hasPathPrefix(pkg, r.Mod.Path)
+	})
+}
+
+func checkRequireDirectives(file *modfile.File, opts Options) []Result {
+	if !opts.RequireCheckDeprecated && !opts.RequireCheckRetracted && !opts.RequireCheckGoVersion {
 		return nil
 	}
 
+	cacheDir := modCacheDir()
+
 	var results []Result
 
-	for _, tool := range file.Tool {
-		results = append(results, NewResult(file, tool.Syntax, reasonTool))
+	var deps []dependency
+
+	for _, req := range effectiveRequirements(file) {
+		dep, err := readCachedGoMod(cacheDir, req.Mod)
+		if err != nil {
+			results = append(results, cachedGoModResult(file, req, err))
+			continue
+		}
+
+		deps = append(deps, dependency{requirement: req, File: dep})
+
+		if opts.RequireCheckDeprecated && dep.Module != nil && dep.Module.Deprecated != "" {
+			reason := fmt.Sprintf(reasonRequireDeprecated, req.Mod.Path, dep.Module.Deprecated)
+			results = append(results, NewResult(file, req.Require.Syntax, reason))
+		}
+
+		if opts.RequireCheckRetracted {
+			results = append(results, checkRequireRetracted(file, cacheDir, req)...)
+		}
+	}
+
+	if opts.RequireCheckGoVersion {
+		results = append(results, checkRequireGoVersion(file, deps)...)
+	}
+
+	return results
+}
+
+// checkRequireGoVersion checks that the go directive (and the toolchain directive, if any)
+// is greater than or equal to the ones of every dependency.
+func checkRequireGoVersion(file *modfile.File, deps []dependency) []Result {
+	var results []Result
+
+	if file.Go != nil {
+		goDep, goVersion := maxDependencyVersion(deps, func(dep *modfile.File) string {
+			if dep.Go == nil {
+				return ""
+			}
+
+			return "go" + dep.Go.Version
+		})
+
+		if goDep != nil && version.Compare("go"+file.Go.Version, goVersion) < 0 {
+			reason := fmt.Sprintf(reasonGoVersionDep, file.Go.Version, goDep.Mod, strings.TrimPrefix(goVersion, "go"))
+			results = append(results, NewResult(file, file.Go.Syntax, reason))
+		}
+	}
+
+	if file.Toolchain != nil && version.IsValid(file.Toolchain.Name) {
+		toolchainDep, toolchain := maxDependencyVersion(deps, laxToolchain)
+
+		if toolchainDep != nil && version.Compare(file.Toolchain.Name, toolchain) < 0 {
+			reason := fmt.Sprintf(reasonToolchainDep, file.Toolchain.Name, toolchainDep.Mod, toolchain)
+			results = append(results, NewResult(file, file.Toolchain.Syntax, reason))
+		}
 	}
 
 	return results
 }
 
+// maxDependencyVersion finds the dependency with the highest Go version (ex: go1.22.1).
+func maxDependencyVersion(deps []dependency, getVersion func(dep *modfile.File) string) (*dependency, string) {
+	var (
+		found   *dependency
+		highest string
+	)
+
+	for i, dep := range deps {
+		v := getVersion(dep.File)
+		if !version.IsValid(v) {
+			continue
+		}
+
+		if found == nil || version.Compare(v, highest) > 0 {
+			found, highest = &deps[i], v
+		}
+	}
+
+	return found, highest
+}
+
+// checkRequireRetracted checks if the required version is retracted by the latest version of the module available in the module cache.
+func checkRequireRetracted(file *modfile.File, cacheDir string, req requirement) []Result {
+	latest, latestVersion, err := readLatestCachedGoMod(cacheDir, req.Mod.Path)
+	if err != nil {
+		return []Result{cachedGoModResult(file, req, err)}
+	}
+
+	for _, retract := range latest.Retract {
+		if !isRetracted(retract, req.Mod.Version) {
+			continue
+		}
+
+		rationale := retract.Rationale
+		if rationale == "" {
+			rationale = "no rationale"
+		}
+
+		reason := fmt.Sprintf(reasonRequireRetracted, req.Mod, latestVersion, rationale)
+
+		return []Result{NewResult(file, req.Require.Syntax, reason)}
+	}
+
+	return nil
+}
+
 func checkReplaceDirectives(file *modfile.File, opts Options) []Result {
 	var results []Result
 
@@ -286,6 +847,11 @@ func checkGoDebugDirectives(file *modfile.File, opts Options) []Result {
 	return results
 }
 
+// hasPathPrefix reports whether the path p begins with the elements in prefix.
+func hasPathPrefix(p, prefix string) bool {
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
 // Filesystem paths found in "replace" directives are represented by a path with an empty version.
 // https://github.com/golang/mod/blob/bc388b264a244501debfb9caea700c6dcaff10e2/module/module.go#L122-L124
 func isLocal(r *modfile.Replace) bool {
@@ -182,6 +182,42 @@ func TestAnalyzeFile(t *testing.T) {
 				End:    token.Position{Filename: "go.mod", Offset: 0, Line: 13, Column: 21},
 			}},
 		},
+		{
+			desc:       "retract: invalid ranges",
+			modulePath: "retract_ranges/go.mod",
+			opts: Options{
+				RetractCheckRanges: true,
+			},
+			expected: []Result{
+				{
+					Reason: "invalid retract range: the low version (v1.2.0) is greater than the high version (v1.1.0)",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 7, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 7, Column: 18},
+				},
+				{
+					Reason: "multiple retractions of the same versions",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 8, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 8, Column: 8},
+				},
+				{
+					Reason: "the retracted versions overlap with another retract directive",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 8},
+				},
+			},
+		},
+		{
+			desc:       "retract: rationale pattern",
+			modulePath: "retract_ranges/go.mod",
+			opts: Options{
+				RetractRationalePattern: regexp.MustCompile(`^CVE-\d+-\d+$`),
+			},
+			expected: []Result{{
+				Reason: "retract rationale (published by mistake) doesn't match the pattern '^CVE-\\d+-\\d+$'",
+				Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 2},
+				End:    token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 8},
+			}},
+		},
 		{
 			desc:       "exclude: don't allow",
 			modulePath: "exclude/go.mod",
@@ -208,6 +244,48 @@ func TestAnalyzeFile(t *testing.T) {
 				ExcludeForbidden: false,
 			},
 		},
+		{
+			desc:       "exclude: allow list",
+			modulePath: "exclude/go.mod",
+			opts: Options{
+				ExcludeForbidden: true,
+				ExcludeAllowList: []string{"golang.org/x/text"},
+			},
+			expected: []Result{{
+				Reason: "exclude directive is not allowed",
+				Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 12, Column: 5},
+				End:    token.Position{Filename: "go.mod", Offset: 0, Line: 12, Column: 31},
+			}},
+		},
+		{
+			desc:       "exclude: don't check require",
+			modulePath: "exclude_require/go.mod",
+			opts:       Options{},
+		},
+		{
+			desc:       "exclude: check require",
+			modulePath: "exclude_require/go.mod",
+			opts: Options{
+				ExcludeCheckRequire: true,
+			},
+			expected: []Result{
+				{
+					Reason: "multiple exclusions of the same module version",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 12, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 12, Column: 31},
+				},
+				{
+					Reason: "the excluded version is the required version: github.com/ldez/grignotin@v0.4.1",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 13, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 13, Column: 34},
+				},
+				{
+					Reason: "the excluded module is not required: golang.org/x/text@v0.3.0",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 14, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 14, Column: 26},
+				},
+			},
+		},
 		{
 			desc:       "ignore: don't allow",
 			modulePath: "ignore/go.mod",
@@ -234,6 +312,19 @@ func TestAnalyzeFile(t *testing.T) {
 				IgnoreForbidden: false,
 			},
 		},
+		{
+			desc:       "ignore: allow list",
+			modulePath: "ignore/go.mod",
+			opts: Options{
+				IgnoreForbidden: true,
+				IgnoreAllowList: []string{"./foo"},
+			},
+			expected: []Result{{
+				Reason: "ignore directive is not allowed",
+				Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 7, Column: 2},
+				End:    token.Position{Filename: "go.mod", Offset: 0, Line: 7, Column: 9},
+			}},
+		},
 		{
 			desc:       "tool: don't allow",
 			modulePath: "tool/go.mod",
@@ -277,6 +368,61 @@ func TestAnalyzeFile(t *testing.T) {
 				ToolForbidden: false,
 			},
 		},
+		{
+			desc:       "tool: allow list",
+			modulePath: "tool_multiple/go.mod",
+			opts: Options{
+				ToolForbidden: true,
+				ToolAllowList: []string{
+					"golang.org/x/tools/cmd/stringer",
+					"example.com/module/cmd/*",
+				},
+			},
+		},
+		{
+			desc:       "tool: allow list (partial)",
+			modulePath: "tool_multiple/go.mod",
+			opts: Options{
+				ToolForbidden: true,
+				ToolAllowList: []string{"example.com/module/cmd/a"},
+			},
+			expected: []Result{
+				{
+					Reason: "tool directive is not allowed",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 5, Column: 1},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 5, Column: 37},
+				},
+				{
+					Reason: "tool directive is not allowed",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 9, Column: 5},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 9, Column: 29},
+				},
+			},
+		},
+		{
+			desc:       "tool: don't check require",
+			modulePath: "tool_consistency/go.mod",
+			opts:       Options{},
+		},
+		{
+			desc:       "tool: check require",
+			modulePath: "tool_consistency/go.mod",
+			opts: Options{
+				ToolCheckRequire: true,
+			},
+			expected: []Result{
+				{
+					Reason: "the tool is not provided by a required module or the main module: example.com/module/cmd/a",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 26},
+				},
+				{
+					Reason: "multiple tool directives for the same package",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 11, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 11, Column: 33},
+				},
+			},
+		},
 		{
 			desc:       "godebug: don't allow",
 			modulePath: "godebug/go.mod",
@@ -415,6 +561,25 @@ func TestAnalyzeFile(t *testing.T) {
 				CheckModulePath: true,
 			},
 		},
+		{
+			desc:       "module path: allowed prefix",
+			modulePath: "module_path/valid/go.mod",
+			opts: Options{
+				ModulePathPrefixes: []string{"go.ourcorp.internal/", "foo.bar.baz/"},
+			},
+		},
+		{
+			desc:       "module path: not allowed prefix",
+			modulePath: "module_path/valid/go.mod",
+			opts: Options{
+				ModulePathPrefixes: []string{"go.ourcorp.internal/", "foo.bar.baz/foo"},
+			},
+			expected: []Result{{
+				Reason: "module path (foo.bar.baz/foobar/baz) doesn't match the allowed prefixes: go.ourcorp.internal/, foo.bar.baz/foo",
+				Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 1, Column: 1},
+				End:    token.Position{Filename: "go.mod", Offset: 0, Line: 1, Column: 30},
+			}},
+		},
 		{
 			desc:       "module path: invalid path with uppercase",
 			modulePath: "module_path/invalid/go.mod",
@@ -523,6 +688,54 @@ func TestAnalyzeFile(t *testing.T) {
 				End:    token.Position{Filename: "go.mod", Offset: 0, Line: 1, Column: 26},
 			}},
 		},
+		{
+			desc:       "deprecated: allow",
+			modulePath: "deprecated/go.mod",
+			opts:       Options{},
+		},
+		{
+			desc:       "deprecated: don't allow",
+			modulePath: "deprecated/go.mod",
+			opts: Options{
+				DeprecatedForbidden: true,
+			},
+			expected: []Result{{
+				Reason: "module deprecation is not allowed",
+				Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 2, Column: 1},
+				End:    token.Position{Filename: "go.mod", Offset: 0, Line: 2, Column: 59},
+			}},
+		},
+		{
+			desc:       "deprecated: pattern match",
+			modulePath: "deprecated/go.mod",
+			opts: Options{
+				DeprecatedPattern: regexp.MustCompile(`^use (?P<module>\S+) instead\.$`),
+			},
+		},
+		{
+			desc:       "deprecated: pattern not matched",
+			modulePath: "deprecated/go.mod",
+			opts: Options{
+				DeprecatedPattern: regexp.MustCompile(`^replaced by (?P<module>\S+)$`),
+			},
+			expected: []Result{{
+				Reason: "deprecation message (use github.com/ldez/gomoddirectives/testdata/v2 instead.) doesn't match the pattern '^replaced by (?P<module>\\S+)$'",
+				Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 2, Column: 1},
+				End:    token.Position{Filename: "go.mod", Offset: 0, Line: 2, Column: 59},
+			}},
+		},
+		{
+			desc:       "deprecated: invalid replacement module",
+			modulePath: "deprecated_invalid/go.mod",
+			opts: Options{
+				DeprecatedPattern: regexp.MustCompile(`^use (\S+) instead\.$`),
+			},
+			expected: []Result{{
+				Reason: "invalid replacement module in the deprecation message: malformed module path \"FOO\": missing dot in first path element",
+				Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 2, Column: 1},
+				End:    token.Position{Filename: "go.mod", Offset: 0, Line: 2, Column: 67},
+			}},
+		},
 		{
 			desc:       "all: empty go.mod",
 			modulePath: "empty/go.mod",
@@ -563,3 +776,113 @@ func TestAnalyzeFile(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeFile_filesystem(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		modulePath string
+		opts       Options
+		expected   []Result
+	}{
+		{
+			desc:       "ignore: check paths",
+			modulePath: "ignore_paths/go.mod",
+			opts: Options{
+				IgnoreCheckPaths: true,
+			},
+			expected: []Result{
+				{
+					Reason: "the ignored path contains Go packages of the module: ./internal",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 8, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 8, Column: 12},
+				},
+				{
+					Reason: "the ignored path doesn't exist: ./missing",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 11},
+				},
+				{
+					Reason: "the ignored path contains Go packages of the module: api/generated",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 12, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 12, Column: 15},
+				},
+				{
+					Reason: "the ignored path doesn't exist: dist",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 13, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 13, Column: 6},
+				},
+			},
+		},
+		{
+			desc:       "ignore: check paths with allow list",
+			modulePath: "ignore_paths/go.mod",
+			opts: Options{
+				IgnoreForbidden:  true,
+				IgnoreAllowList:  []string{"./node_modules", "./third_party", "bower_components", "api", "dist"},
+				IgnoreCheckPaths: true,
+			},
+			expected: []Result{
+				{
+					Reason: "ignore directive is not allowed",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 8, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 8, Column: 12},
+				},
+				{
+					Reason: "ignore directive is not allowed",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 9, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 9, Column: 9},
+				},
+				{
+					Reason: "ignore directive is not allowed",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 10, Column: 11},
+				},
+				{
+					Reason: "the ignored path contains Go packages of the module: api/generated",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 12, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 12, Column: 15},
+				},
+				{
+					Reason: "the ignored path doesn't exist: dist",
+					Start:  token.Position{Filename: "go.mod", Offset: 0, Line: 13, Column: 2},
+					End:    token.Position{Filename: "go.mod", Offset: 0, Line: 13, Column: 6},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.FromSlash("./testdata/" + test.modulePath)
+
+			raw, err := os.ReadFile(filename)
+			require.NoError(t, err)
+
+			file, err := modfile.Parse("go.mod", raw, nil)
+			require.NoError(t, err)
+
+			results := AnalyzeFile(file, test.opts.WithModuleDir(filepath.Dir(filename)))
+
+			slices.SortFunc(results, func(a, b Result) int {
+				return cmp.Or(cmp.Compare(a.Start.Line, b.Start.Line), cmp.Compare(a.End.Line, b.End.Line))
+			})
+
+			assert.Equal(t, test.expected, results)
+		})
+	}
+}
+
+func TestResult_String(t *testing.T) {
+	result := Result{
+		Reason: "foo",
+		Start:  token.Position{Filename: "go.mod", Line: 3, Column: 2},
+	}
+
+	assert.Equal(t, "go.mod:3:2: foo", result.String())
+
+	result.Severity = SeverityInfo
+
+	assert.Equal(t, "go.mod:3:2: info: foo", result.String())
+}
@@ -0,0 +1,179 @@
+package gomoddirectives
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// The toolchain module is downloaded by the go command when switching toolchains, it's not a part of the module graph.
+const toolchainModulePath = "golang.org/toolchain"
+
+// sumLine a line of a go.sum file.
+type sumLine struct {
+	Line int
+	Text string
+	Mod  module.Version
+	// GoMod true if the hash is the hash of the go.mod file only.
+	GoMod bool
+	Hash  string
+}
+
+// Result creates a result positioned on the go.sum line.
+func (l sumLine) Result(filename, reason string) Result {
+	return Result{
+		Start:  token.Position{Filename: filename, Line: l.Line, Column: 1},
+		End:    token.Position{Filename: filename, Line: l.Line, Column: len(l.Text) + 1},
+		Reason: reason,
+	}
+}
+
+// parseGoSum parses a go.sum file.
+// Returns an error that wraps [fs.ErrNotExist] if the file doesn't exist.
+func parseGoSum(filename string) ([]sumLine, []Result, error) {
+	f, err := os.Open(filepath.Clean(filename))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	var (
+		lines   []sumLine
+		results []Result
+	)
+
+	scanner := bufio.NewScanner(f)
+
+	for i := 1; scanner.Scan(); i++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 3 {
+			results = append(results, sumLine{Line: i, Text: text}.Result(filename, reasonGoSumMalformed))
+			continue
+		}
+
+		v, goMod := strings.CutSuffix(fields[1], "/go.mod")
+
+		lines = append(lines, sumLine{
+			Line:  i,
+			Text:  text,
+			Mod:   module.Version{Path: fields[0], Version: v},
+			GoMod: goMod,
+			Hash:  fields[2],
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", filename, err)
+	}
+
+	return lines, results, nil
+}
+
+// checkGoSum checks the consistency of the go.sum file located next to the module file.
+func checkGoSum(file *modfile.File, opts Options) []Result {
+	if !opts.CheckGoSum || file.Module == nil {
+		return nil
+	}
+
+	filename := filepath.Join(moduleDir(file, opts), "go.sum")
+
+	lines, results, err := parseGoSum(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return []Result{NewResult(file, file.Module.Syntax, err.Error())}
+	}
+
+	goModHashes := map[module.Version]struct{}{}
+	for _, line := range lines {
+		if line.GoMod {
+			goModHashes[line.Mod] = struct{}{}
+		}
+	}
+
+	// requirements and replacements without the hash of their go.mod file.
+	for _, req := range effectiveRequirements(file) {
+		if _, ok := goModHashes[req.Mod]; ok {
+			continue
+		}
+
+		syntax := req.Require.Syntax
+		if req.Replace != nil {
+			syntax = req.Replace.Syntax
+		}
+
+		results = append(results, NewResult(file, syntax, fmt.Sprintf(reasonGoSumMissing, req.Mod)))
+	}
+
+	results = append(results, checkGoSumLines(file, filename, lines)...)
+
+	return results
+}
+
+func checkGoSumLines(file *modfile.File, filename string, lines []sumLine) []Result {
+	var results []Result
+
+	localReplaced := localReplacedVersions(file)
+
+	graph, complete := moduleGraph(file, filepath.Dir(filename), modCacheDir())
+
+	type sumKey struct {
+		Mod   module.Version
+		GoMod bool
+		Alg   string
+	}
+
+	hashes := map[sumKey]string{}
+
+	for _, line := range lines {
+		alg, _, _ := strings.Cut(line.Hash, ":")
+		key := sumKey{Mod: line.Mod, GoMod: line.GoMod, Alg: alg}
+
+		if hash, ok := hashes[key]; ok && hash != line.Hash {
+			results = append(results, line.Result(filename, fmt.Sprintf(reasonGoSumConflict, line.Mod)))
+			continue
+		}
+
+		hashes[key] = line.Hash
+
+		switch {
+		case localReplaced[line.Mod]:
+			results = append(results, line.Result(filename, fmt.Sprintf(reasonGoSumLocal, line.Mod)))
+
+		case complete && !graph[line.Mod] && line.Mod.Path != toolchainModulePath:
+			results = append(results, line.Result(filename, fmt.Sprintf(reasonGoSumStale, line.Mod)))
+		}
+	}
+
+	return results
+}
+
+// localReplacedVersions returns the required module versions replaced by a local directory.
+func localReplacedVersions(file *modfile.File) map[module.Version]bool {
+	versions := map[module.Version]bool{}
+
+	for _, req := range file.Require {
+		replace := findReplace(file, req.Mod)
+		if replace != nil && isLocal(replace) {
+			versions[req.Mod] = true
+		}
+	}
+
+	return versions
+}
@@ -0,0 +1,81 @@
+package gomoddirectives
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeFile_goSum(t *testing.T) {
+	setupModCache(t)
+
+	file := parseTestdata(t, "gosum/go.mod")
+
+	results := AnalyzeFile(file, Options{ReplaceAllowLocal: true, CheckGoSum: true})
+
+	filename := "testdata/gosum/go.mod"
+	sumFilename := filepath.Join("testdata", "gosum", "go.sum")
+
+	expected := []Result{
+		{
+			Reason: "malformed go.sum line",
+			Start:  token.Position{Filename: sumFilename, Line: 10, Column: 1},
+			End:    token.Position{Filename: sumFilename, Line: 10, Column: 17},
+		},
+		{
+			Reason: "missing go.sum entry for the go.mod file of example.com/newgo@v1.0.0",
+			Start:  token.Position{Filename: filename, Line: 8, Column: 2},
+			End:    token.Position{Filename: filename, Line: 8, Column: 26},
+		},
+		{
+			Reason: "go.sum contains an entry for example.com/local@v1.0.0, but the module is replaced by a local directory",
+			Start:  token.Position{Filename: sumFilename, Line: 4, Column: 1},
+			End:    token.Position{Filename: sumFilename, Line: 4, Column: 80},
+		},
+		{
+			Reason: "go.sum contains an entry for example.com/stale@v0.1.0, but the module is not in the module graph",
+			Start:  token.Position{Filename: sumFilename, Line: 7, Column: 1},
+			End:    token.Position{Filename: sumFilename, Line: 7, Column: 80},
+		},
+		{
+			Reason: "go.sum contains conflicting hashes for example.com/fine@v1.2.0",
+			Start:  token.Position{Filename: sumFilename, Line: 8, Column: 1},
+			End:    token.Position{Filename: sumFilename, Line: 8, Column: 72},
+		},
+	}
+
+	assert.Equal(t, expected, results)
+}
+
+func TestAnalyzeFile_goSum_incompleteGraph(t *testing.T) {
+	t.Setenv("GOMODCACHE", t.TempDir())
+
+	file := parseTestdata(t, "gosum/go.mod")
+
+	results := AnalyzeFile(file, Options{ReplaceAllowLocal: true, CheckGoSum: true})
+
+	var reasons []string
+	for _, result := range results {
+		reasons = append(reasons, result.Reason)
+	}
+
+	// the stale entries are not reported when the module graph is incomplete.
+	expected := []string{
+		"malformed go.sum line",
+		"missing go.sum entry for the go.mod file of example.com/newgo@v1.0.0",
+		"go.sum contains an entry for example.com/local@v1.0.0, but the module is replaced by a local directory",
+		"go.sum contains conflicting hashes for example.com/fine@v1.2.0",
+	}
+
+	assert.Equal(t, expected, reasons)
+}
+
+func TestAnalyzeFile_goSum_noGoSum(t *testing.T) {
+	file := parseTestdata(t, "replace/go.mod")
+
+	results := AnalyzeFile(file, Options{ReplaceAllowAll: true, CheckGoSum: true})
+
+	assert.Empty(t, results)
+}
@@ -0,0 +1,436 @@
+package gomoddirectives
+
+import (
+	"cmp"
+	"fmt"
+	"go/token"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// The kind of the require directives with a majority of indirect dependencies.
+const requireIndirectKind = "require-indirect"
+
+// DefaultLayoutOrder the default order of the directives used by the layout check.
+var DefaultLayoutOrder = []string{
+	"module",
+	"go",
+	"toolchain",
+	"godebug",
+	"require",
+	requireIndirectKind,
+	"replace",
+	"exclude",
+	"retract",
+	"tool",
+	"ignore",
+}
+
+// layoutUnit a directive (line or block) and the comment blocks placed before it.
+type layoutUnit struct {
+	Stmts []modfile.Expr
+	Rank  int
+}
+
+// checkLayout checks the order of the directives, the order of the requirements inside the require blocks,
+// and the use of a block for the replace directives.
+// The fix rewrites the whole file.
+func checkLayout(file *modfile.File, opts Options) []Result {
+	if !opts.CheckLayout {
+		return nil
+	}
+
+	order := opts.LayoutOrder
+	if len(order) == 0 {
+		order = DefaultLayoutOrder
+	}
+
+	var results []Result
+
+	results = append(results, checkLayoutOrder(file, order)...)
+	results = append(results, checkLayoutRequireSorted(file)...)
+	results = append(results, checkLayoutReplaceBlock(file)...)
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	edits := layoutEdits(file, opts, order)
+
+	for i := range results {
+		results[i].Edits = edits
+	}
+
+	return results
+}
+
+func checkLayoutOrder(file *modfile.File, order []string) []Result {
+	var results []Result
+
+	var (
+		previous     string
+		previousRank = -1
+	)
+
+	for _, stmt := range file.Syntax.Stmt {
+		kind := layoutKind(order, stmtKind(file, stmt))
+
+		rank := slices.Index(order, kind)
+		if rank < 0 {
+			continue
+		}
+
+		if rank < previousRank {
+			results = append(results, newExprResult(file, stmt, fmt.Sprintf(reasonLayoutOrder, kind, previous)))
+			continue
+		}
+
+		previous, previousRank = kind, rank
+	}
+
+	return results
+}
+
+func checkLayoutRequireSorted(file *modfile.File) []Result {
+	var results []Result
+
+	for _, stmt := range file.Syntax.Stmt {
+		block, ok := stmt.(*modfile.LineBlock)
+		if !ok || block.Token[0] != "require" {
+			continue
+		}
+
+		for i := 1; i < len(block.Line); i++ {
+			if compareRequireLines(block.Line[i-1], block.Line[i]) > 0 {
+				results = append(results, NewResult(file, block.Line[i], fmt.Sprintf(reasonLayoutSorted, block.Line[i].Token[0])))
+			}
+		}
+	}
+
+	return results
+}
+
+func checkLayoutReplaceBlock(file *modfile.File) []Result {
+	if len(file.Replace) < 2 {
+		return nil
+	}
+
+	var (
+		results []Result
+		blocks  int
+	)
+
+	for _, stmt := range file.Syntax.Stmt {
+		switch x := stmt.(type) {
+		case *modfile.Line:
+			if x.Token[0] == "replace" {
+				results = append(results, NewResult(file, x, reasonLayoutReplaceBlock))
+			}
+
+		case *modfile.LineBlock:
+			if x.Token[0] != "replace" {
+				continue
+			}
+
+			blocks++
+
+			if blocks > 1 {
+				results = append(results, newExprResult(file, x, reasonLayoutReplaceBlock))
+			}
+		}
+	}
+
+	return results
+}
+
+// layoutEdits computes the edit to rewrite the file with the expected layout.
+func layoutEdits(file *modfile.File, opts Options, order []string) []TextEdit {
+	return rewriteEdits(file, opts, func(clone *modfile.File) {
+		mergeReplaceBlocks(clone)
+
+		for _, stmt := range clone.Syntax.Stmt {
+			block, ok := stmt.(*modfile.LineBlock)
+			if ok && block.Token[0] == "require" {
+				slices.SortStableFunc(block.Line, compareRequireLines)
+			}
+		}
+
+		units := layoutUnits(clone, order)
+
+		slices.SortStableFunc(units, func(a, b layoutUnit) int {
+			return cmp.Compare(a.Rank, b.Rank)
+		})
+
+		clone.Syntax.Stmt = nil
+		for _, unit := range units {
+			clone.Syntax.Stmt = append(clone.Syntax.Stmt, unit.Stmts...)
+		}
+	})
+}
+
+// rewriteEdits computes the edit to rewrite the whole file with the changes applied by the rewrite function.
+// The module file is cloned (by parsing its canonical formatting) to keep the original file unchanged.
+func rewriteEdits(file *modfile.File, opts Options, rewrite func(clone *modfile.File)) []TextEdit {
+	clone, err := modfile.Parse(file.Syntax.Name, modfile.Format(file.Syntax), nil)
+	if err != nil {
+		return nil
+	}
+
+	rewrite(clone)
+
+	original, err := readContent(file, opts)
+	if err != nil {
+		return nil
+	}
+
+	return []TextEdit{{Start: 0, End: len(original), NewText: modfile.Format(clone.Syntax)}}
+}
+
+// layoutUnits groups the statements into units: the comment blocks are attached to the next directive.
+// The directives not defined by the order are not checked: they follow the previous directive.
+func layoutUnits(file *modfile.File, order []string) []layoutUnit {
+	var (
+		units   []layoutUnit
+		pending []modfile.Expr
+	)
+
+	previousRank := -1
+
+	for _, stmt := range file.Syntax.Stmt {
+		pending = append(pending, stmt)
+
+		if _, ok := stmt.(*modfile.CommentBlock); ok {
+			continue
+		}
+
+		rank := slices.Index(order, layoutKind(order, stmtKind(file, stmt)))
+		if rank < 0 {
+			rank = previousRank
+		}
+
+		units = append(units, layoutUnit{Stmts: pending, Rank: rank})
+		pending = nil
+		previousRank = rank
+	}
+
+	if len(pending) > 0 {
+		units = append(units, layoutUnit{Stmts: pending, Rank: len(order) + 1})
+	}
+
+	return units
+}
+
+// layoutKind returns the kind of directive used by the order.
+// When the order doesn't define the require-indirect directives, they are require directives.
+func layoutKind(order []string, kind string) string {
+	if kind == requireIndirectKind && !slices.Contains(order, requireIndirectKind) {
+		return "require"
+	}
+
+	return kind
+}
+
+// mergeReplaceBlocks moves all the replace directives into the first replace block (or in a new block).
+func mergeReplaceBlocks(file *modfile.File) {
+	if len(file.Replace) < 2 {
+		return
+	}
+
+	var (
+		block *modfile.LineBlock
+		stmts []modfile.Expr
+	)
+
+	for _, stmt := range file.Syntax.Stmt {
+		switch x := stmt.(type) {
+		case *modfile.Line:
+			if x.Token[0] != "replace" {
+				break
+			}
+
+			x.Token = x.Token[1:]
+			x.InBlock = true
+
+			if block == nil {
+				block = &modfile.LineBlock{Token: []string{"replace"}}
+				stmts = append(stmts, block)
+			}
+
+			block.Line = append(block.Line, x)
+
+			continue
+
+		case *modfile.LineBlock:
+			if x.Token[0] != "replace" {
+				break
+			}
+
+			if block == nil {
+				block = x
+				stmts = append(stmts, block)
+
+				continue
+			}
+
+			block.Line = append(block.Line, x.Line...)
+
+			continue
+		}
+
+		stmts = append(stmts, stmt)
+	}
+
+	file.Syntax.Stmt = stmts
+}
+
+// stmtKind returns the kind of directive of a statement.
+// A require block, or a require directive, with a majority of indirect dependencies is a `require-indirect` directive.
+func stmtKind(file *modfile.File, stmt modfile.Expr) string {
+	switch x := stmt.(type) {
+	case *modfile.Line:
+		if x.Token[0] == "require" && isIndirectLine(file, x) {
+			return requireIndirectKind
+		}
+
+		return x.Token[0]
+
+	case *modfile.LineBlock:
+		if x.Token[0] != "require" {
+			return x.Token[0]
+		}
+
+		var indirect int
+
+		for _, line := range x.Line {
+			if isIndirectLine(file, line) {
+				indirect++
+			}
+		}
+
+		if indirect*2 > len(x.Line) {
+			return requireIndirectKind
+		}
+
+		return x.Token[0]
+
+	default:
+		return ""
+	}
+}
+
+func isIndirectLine(file *modfile.File, line *modfile.Line) bool {
+	for _, req := range file.Require {
+		if req.Syntax == line {
+			return req.Indirect
+		}
+	}
+
+	return false
+}
+
+// compareRequireLines compares 2 lines of a require block by module path, then by version.
+func compareRequireLines(a, b *modfile.Line) int {
+	if len(a.Token) < 2 || len(b.Token) < 2 {
+		return 0
+	}
+
+	return cmp.Or(cmp.Compare(a.Token[0], b.Token[0]), semver.Compare(a.Token[1], b.Token[1]))
+}
+
+// newExprResult creates a new Result for a line or a block.
+func newExprResult(file *modfile.File, expr modfile.Expr, reason string) Result {
+	start, end := expr.Span()
+
+	return Result{
+		Start:  token.Position{Filename: file.Syntax.Name, Line: start.Line, Column: start.LineRune},
+		End:    token.Position{Filename: file.Syntax.Name, Line: end.Line, Column: end.LineRune},
+		Reason: reason,
+	}
+}
+
+// checkRequireBlocks checks that the direct and the indirect requirements are in separate blocks,
+// and that a module is not required with different versions.
+// The fix regroups the requirements in a block of direct requirements and a block of indirect requirements.
+func checkRequireBlocks(file *modfile.File, opts Options) []Result {
+	if !opts.RequireCheckBlocks {
+		return nil
+	}
+
+	var results []Result
+
+	for _, stmt := range file.Syntax.Stmt {
+		block, ok := stmt.(*modfile.LineBlock)
+		if !ok || block.Token[0] != "require" {
+			continue
+		}
+
+		indirectBlock := stmtKind(file, block) == requireIndirectKind
+
+		for _, line := range block.Line {
+			switch indirect := isIndirectLine(file, line); {
+			case indirect && !indirectBlock:
+				results = append(results, NewResult(file, line, fmt.Sprintf(reasonRequireIndirectBlock, line.Token[0])))
+			case !indirect && indirectBlock:
+				results = append(results, NewResult(file, line, fmt.Sprintf(reasonRequireDirectBlock, line.Token[0])))
+			}
+		}
+	}
+
+	versions := map[string]string{}
+
+	for _, req := range file.Require {
+		v, ok := versions[req.Mod.Path]
+		if !ok {
+			versions[req.Mod.Path] = req.Mod.Version
+			continue
+		}
+
+		if v != req.Mod.Version {
+			results = append(results, NewResult(file, req.Syntax, fmt.Sprintf(reasonRequireDuplicate, req.Mod.Path, v, req.Mod.Version)))
+		}
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	edits := rewriteEdits(file, opts, func(clone *modfile.File) {
+		clone.SetRequireAtMostTwo(mergeRequirements(clone.Require))
+		clone.Cleanup()
+	})
+
+	for i := range results {
+		results[i].Edits = edits
+	}
+
+	return results
+}
+
+// mergeRequirements merges the requirements of the same module:
+// the highest version is kept, and the requirement is indirect only if all the requirements are indirect.
+func mergeRequirements(requires []*modfile.Require) []*modfile.Require {
+	var merged []*modfile.Require
+
+	byPath := map[string]*modfile.Require{}
+
+	for _, req := range requires {
+		current, ok := byPath[req.Mod.Path]
+		if !ok {
+			current = &modfile.Require{Mod: req.Mod, Indirect: req.Indirect}
+			byPath[req.Mod.Path] = current
+			merged = append(merged, current)
+
+			continue
+		}
+
+		if semver.Compare(req.Mod.Version, current.Mod.Version) > 0 {
+			current.Mod.Version = req.Mod.Version
+		}
+
+		current.Indirect = current.Indirect && req.Indirect
+	}
+
+	return merged
+}
@@ -0,0 +1,255 @@
+package gomoddirectives
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unorderedGoMod = `module example.com/foo
+
+require (
+	github.com/c/d v1.2.0 // indirect
+	github.com/e/f v1.0.0 // indirect
+)
+
+require (
+	github.com/b/b v1.0.0
+	github.com/a/a v1.0.0
+)
+
+replace github.com/a/a => ../a
+
+// comment of the go directive
+go 1.22
+
+replace github.com/b/b => ../b
+`
+
+const orderedGoMod = `module example.com/foo
+
+// comment of the go directive
+go 1.22
+
+require (
+	github.com/a/a v1.0.0
+	github.com/b/b v1.0.0
+)
+
+require (
+	github.com/c/d v1.2.0 // indirect
+	github.com/e/f v1.0.0 // indirect
+)
+
+replace (
+	github.com/a/a => ../a
+	github.com/b/b => ../b
+)
+`
+
+func TestAnalyzeFile_layout(t *testing.T) {
+	file := writeGoMod(t, t.TempDir(), unorderedGoMod)
+
+	results := AnalyzeFile(file, Options{ReplaceAllowAll: true, CheckLayout: true})
+
+	filename := file.Syntax.Name
+
+	edits := []TextEdit{{Start: 0, End: len(unorderedGoMod), NewText: []byte(orderedGoMod)}}
+
+	expected := []Result{
+		{
+			Reason: "require directive should be placed before the require-indirect directive",
+			Start:  token.Position{Filename: filename, Line: 8, Column: 1},
+			End:    token.Position{Filename: filename, Line: 11, Column: 2},
+			Edits:  edits,
+		},
+		{
+			Reason: "go directive should be placed before the replace directive",
+			Start:  token.Position{Filename: filename, Line: 16, Column: 1},
+			End:    token.Position{Filename: filename, Line: 16, Column: 8},
+			Edits:  edits,
+		},
+		{
+			Reason: "github.com/a/a is not sorted in the require block",
+			Start:  token.Position{Filename: filename, Line: 10, Column: 2},
+			End:    token.Position{Filename: filename, Line: 10, Column: 23},
+			Edits:  edits,
+		},
+		{
+			Reason: "replace directives should be grouped in a single block",
+			Start:  token.Position{Filename: filename, Line: 13, Column: 1},
+			End:    token.Position{Filename: filename, Line: 13, Column: 31},
+			Edits:  edits,
+		},
+		{
+			Reason: "replace directives should be grouped in a single block",
+			Start:  token.Position{Filename: filename, Line: 18, Column: 1},
+			End:    token.Position{Filename: filename, Line: 18, Column: 31},
+			Edits:  edits,
+		},
+	}
+
+	assert.Equal(t, expected, results)
+
+	fixed, err := ApplyEdits([]byte(unorderedGoMod), results[0].Edits)
+	require.NoError(t, err)
+
+	assert.Equal(t, orderedGoMod, string(fixed))
+
+	assert.Empty(t, AnalyzeFile(writeGoMod(t, t.TempDir(), orderedGoMod), Options{ReplaceAllowAll: true, CheckLayout: true}))
+}
+
+func TestAnalyzeFile_layout_order(t *testing.T) {
+	file := writeGoMod(t, t.TempDir(), orderedGoMod)
+
+	opts := Options{
+		ReplaceAllowAll: true,
+		CheckLayout:     true,
+		LayoutOrder:     []string{"module", "go", "replace", "require"},
+	}
+
+	results := AnalyzeFile(file, opts)
+
+	require.Len(t, results, 1)
+
+	assert.Equal(t, "replace directive should be placed before the require directive", results[0].Reason)
+	assert.Equal(t, 16, results[0].Start.Line)
+
+	// the order doesn't define require-indirect: the indirect requirements stay with the direct requirements.
+	expected := `module example.com/foo
+
+// comment of the go directive
+go 1.22
+
+replace (
+	github.com/a/a => ../a
+	github.com/b/b => ../b
+)
+
+require (
+	github.com/a/a v1.0.0
+	github.com/b/b v1.0.0
+)
+
+require (
+	github.com/c/d v1.2.0 // indirect
+	github.com/e/f v1.0.0 // indirect
+)
+`
+
+	require.Len(t, results[0].Edits, 1)
+	assert.Equal(t, expected, string(results[0].Edits[0].NewText))
+}
+
+func TestAnalyzeFile_layout_order_undefined(t *testing.T) {
+	file := writeGoMod(t, t.TempDir(), `module example.com/foo
+
+go 1.22
+
+require github.com/a/a v1.0.0
+
+require github.com/b/b v1.0.0 // indirect
+
+exclude github.com/a/a v0.9.0
+
+replace github.com/a/a => ../a
+`)
+
+	opts := Options{
+		ReplaceAllowAll: true,
+		CheckLayout:     true,
+		LayoutOrder:     []string{"module", "go", "replace", "require"},
+	}
+
+	results := AnalyzeFile(file, opts)
+
+	require.Len(t, results, 1)
+
+	assert.Equal(t, "replace directive should be placed before the require directive", results[0].Reason)
+
+	// the exclude directive is not defined by the order: it follows the indirect requirement.
+	expected := `module example.com/foo
+
+go 1.22
+
+replace github.com/a/a => ../a
+
+require github.com/a/a v1.0.0
+
+require github.com/b/b v1.0.0 // indirect
+
+exclude github.com/a/a v0.9.0
+`
+
+	require.Len(t, results[0].Edits, 1)
+	assert.Equal(t, expected, string(results[0].Edits[0].NewText))
+}
+
+const mixedRequireGoMod = `module example.com/foo
+
+go 1.22
+
+require (
+	github.com/a/a v1.0.0
+	github.com/b/b v1.0.0 // indirect
+	github.com/c/c v1.0.0
+)
+
+require (
+	github.com/d/d v1.0.0 // indirect
+	github.com/e/e v1.0.0 // indirect
+	github.com/a/a v1.1.0 // indirect
+)
+`
+
+const separatedRequireGoMod = `module example.com/foo
+
+go 1.22
+
+require (
+	github.com/a/a v1.1.0
+	github.com/c/c v1.0.0
+)
+
+require (
+	github.com/b/b v1.0.0 // indirect
+	github.com/d/d v1.0.0 // indirect
+	github.com/e/e v1.0.0 // indirect
+)
+`
+
+func TestAnalyzeFile_requireBlocks(t *testing.T) {
+	file := writeGoMod(t, t.TempDir(), mixedRequireGoMod)
+
+	results := AnalyzeFile(file, Options{RequireCheckBlocks: true})
+
+	filename := file.Syntax.Name
+
+	edits := []TextEdit{{Start: 0, End: len(mixedRequireGoMod), NewText: []byte(separatedRequireGoMod)}}
+
+	expected := []Result{
+		{
+			Reason: "the indirect requirement github.com/b/b is in a block of direct requirements",
+			Start:  token.Position{Filename: filename, Line: 7, Column: 2},
+			End:    token.Position{Filename: filename, Line: 7, Column: 23},
+			Edits:  edits,
+		},
+		{
+			Reason: "multiple requirements of github.com/a/a with different versions (v1.0.0, v1.1.0)",
+			Start:  token.Position{Filename: filename, Line: 14, Column: 2},
+			End:    token.Position{Filename: filename, Line: 14, Column: 23},
+			Edits:  edits,
+		},
+	}
+
+	assert.Equal(t, expected, results)
+
+	fixed, err := ApplyEdits([]byte(mixedRequireGoMod), results[0].Edits)
+	require.NoError(t, err)
+
+	assert.Equal(t, separatedRequireGoMod, string(fixed))
+
+	assert.Empty(t, AnalyzeFile(writeGoMod(t, t.TempDir(), separatedRequireGoMod), Options{RequireCheckBlocks: true}))
+}
@@ -0,0 +1,284 @@
+package gomoddirectives
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// requirement a required module and the module version used to build it (after replacement).
+type requirement struct {
+	Require *modfile.Require
+	Replace *modfile.Replace
+	Mod     module.Version
+}
+
+// dependency a requirement and the module file of the required module version.
+type dependency struct {
+	requirement
+
+	File *modfile.File
+}
+
+// effectiveRequirements returns the requirements of a module file, with the replacements applied.
+// The requirements replaced by a local directory are skipped.
+func effectiveRequirements(file *modfile.File) []requirement {
+	var reqs []requirement
+
+	for _, req := range file.Require {
+		mod := req.Mod
+
+		replace := findReplace(file, req.Mod)
+		if replace != nil {
+			if isLocal(replace) {
+				continue
+			}
+
+			mod = replace.New
+		}
+
+		reqs = append(reqs, requirement{Require: req, Replace: replace, Mod: mod})
+	}
+
+	return reqs
+}
+
+// findReplace finds the replace directive that applies to a module version.
+// A replacement of a specific version takes precedence over a replacement of all the versions.
+func findReplace(file *modfile.File, mod module.Version) *modfile.Replace {
+	var found *modfile.Replace
+
+	for _, replace := range file.Replace {
+		if replace.Old.Path != mod.Path {
+			continue
+		}
+
+		if replace.Old.Version == mod.Version {
+			return replace
+		}
+
+		if replace.Old.Version == "" {
+			found = replace
+		}
+	}
+
+	return found
+}
+
+// moduleGraph walks the module graph: the go.mod files of the dependencies are read from the module cache,
+// and the replacements of the main module are applied.
+// The local replacements are resolved from the directory of the main module.
+// Returns false if a go.mod file of the graph is missing.
+func moduleGraph(file *modfile.File, root, cacheDir string) (map[module.Version]bool, bool) {
+	graph := map[module.Version]bool{}
+	visitedLocal := map[string]bool{}
+
+	complete := true
+
+	queue := []*modfile.File{file}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, req := range current.Require {
+			var (
+				dep *modfile.File
+				err error
+			)
+
+			replace := findReplace(file, req.Mod)
+
+			switch {
+			case replace != nil && isLocal(replace):
+				dir := replace.New.Path
+				if !filepath.IsAbs(dir) {
+					dir = filepath.Join(root, filepath.FromSlash(dir))
+				}
+
+				if visitedLocal[dir] {
+					continue
+				}
+
+				visitedLocal[dir] = true
+
+				dep, err = readLocalGoMod(dir)
+
+			default:
+				mod := req.Mod
+				if replace != nil {
+					mod = replace.New
+				}
+
+				if graph[mod] {
+					continue
+				}
+
+				graph[mod] = true
+
+				dep, err = readCachedGoMod(cacheDir, mod)
+			}
+
+			if err != nil {
+				complete = false
+				continue
+			}
+
+			queue = append(queue, dep)
+		}
+	}
+
+	return graph, complete
+}
+
+func readLocalGoMod(dir string) (*modfile.File, error) {
+	filename := filepath.Join(dir, "go.mod")
+
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return modfile.ParseLax(filename, raw, nil)
+}
+
+// modCacheDir returns the module cache directory without calling the go command.
+func modCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = build.Default.GOPATH
+	}
+
+	list := filepath.SplitList(gopath)
+	if len(list) == 0 {
+		return ""
+	}
+
+	return filepath.Join(list[0], "pkg", "mod")
+}
+
+// readCachedGoMod reads the go.mod file of a module version from the download cache of the module cache:
+// `<GOMODCACHE>/cache/download/<path>/@v/<version>.mod`.
+// Returns an error that wraps [fs.ErrNotExist] if the module version is not in the cache.
+func readCachedGoMod(cacheDir string, mod module.Version) (*modfile.File, error) {
+	escPath, err := module.EscapePath(mod.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	escVersion, err := module.EscapeVersion(mod.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := filepath.Join(cacheDir, "cache", "download", filepath.FromSlash(escPath), "@v", escVersion+".mod")
+
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := modfile.ParseLax(filename, raw, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filename, err)
+	}
+
+	return file, nil
+}
+
+// readLatestCachedGoMod reads the go.mod file of the latest version of a module available in the download cache of the module cache.
+// The latest version is the highest release version, or the highest pre-release version if there is no release.
+// Returns an error that wraps [fs.ErrNotExist] if no version of the module is in the cache.
+func readLatestCachedGoMod(cacheDir, modPath string) (*modfile.File, string, error) {
+	escPath, err := module.EscapePath(modPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(cacheDir, "cache", "download", filepath.FromSlash(escPath), "@v"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var latest string
+
+	for _, entry := range entries {
+		escVersion, ok := strings.CutSuffix(entry.Name(), ".mod")
+		if !ok {
+			continue
+		}
+
+		v, err := module.UnescapeVersion(escVersion)
+		if err != nil || !semver.IsValid(v) {
+			continue
+		}
+
+		if latest == "" || isLatestCandidate(v, latest) {
+			latest = v
+		}
+	}
+
+	if latest == "" {
+		return nil, "", fmt.Errorf("no version of %s: %w", modPath, fs.ErrNotExist)
+	}
+
+	file, err := readCachedGoMod(cacheDir, module.Version{Path: modPath, Version: latest})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return file, latest, nil
+}
+
+// isLatestCandidate checks if v must be preferred over the current latest version.
+func isLatestCandidate(v, latest string) bool {
+	vPre, latestPre := semver.Prerelease(v) != "", semver.Prerelease(latest) != ""
+
+	if vPre != latestPre {
+		return !vPre
+	}
+
+	return semver.Compare(v, latest) > 0
+}
+
+// laxToolchain returns the name of the toolchain of a module file parsed by [modfile.ParseLax].
+// The toolchain directive is ignored by the lax parsing, so the name is read from the syntax tree.
+func laxToolchain(file *modfile.File) string {
+	if file.Toolchain != nil {
+		return file.Toolchain.Name
+	}
+
+	for _, stmt := range file.Syntax.Stmt {
+		line, ok := stmt.(*modfile.Line)
+		if ok && len(line.Token) == 2 && line.Token[0] == "toolchain" {
+			return line.Token[1]
+		}
+	}
+
+	return ""
+}
+
+// cachedGoModResult creates a result for an error that occurs while reading the module cache.
+// A module version missing from the module cache is only an informational note.
+func cachedGoModResult(file *modfile.File, req requirement, err error) Result {
+	if !errors.Is(err, fs.ErrNotExist) {
+		return NewResult(file, req.Require.Syntax, err.Error())
+	}
+
+	result := NewResult(file, req.Require.Syntax, fmt.Sprintf("%s: %s", reasonNotInCache, req.Mod))
+	result.Severity = SeverityInfo
+
+	return result
+}
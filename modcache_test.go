@@ -0,0 +1,183 @@
+package gomoddirectives
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// setupModCache uses the fake module cache from the testdata.
+func setupModCache(t *testing.T) {
+	t.Helper()
+
+	dir, err := filepath.Abs(filepath.Join("testdata", "modcache"))
+	require.NoError(t, err)
+
+	t.Setenv("GOMODCACHE", dir)
+}
+
+func parseTestdata(t *testing.T, modulePath string) *modfile.File {
+	t.Helper()
+
+	filename := "testdata/" + modulePath
+
+	raw, err := os.ReadFile(filepath.FromSlash(filename))
+	require.NoError(t, err)
+
+	file, err := modfile.Parse(filename, raw, nil)
+	require.NoError(t, err)
+
+	return file
+}
+
+func Test_modCacheDir(t *testing.T) {
+	t.Setenv("GOMODCACHE", "")
+	t.Setenv("GOPATH", filepath.Join("a", "b")+string(filepath.ListSeparator)+filepath.Join("c", "d"))
+
+	assert.Equal(t, filepath.Join("a", "b", "pkg", "mod"), modCacheDir())
+
+	t.Setenv("GOMODCACHE", filepath.Join("e", "f"))
+
+	assert.Equal(t, filepath.Join("e", "f"), modCacheDir())
+}
+
+func Test_readCachedGoMod(t *testing.T) {
+	cacheDir := filepath.Join("testdata", "modcache")
+
+	file, err := readCachedGoMod(cacheDir, module.Version{Path: "example.com/Upper", Version: "v1.0.0"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com/Upper", file.Module.Mod.Path)
+
+	_, err = readCachedGoMod(cacheDir, module.Version{Path: "example.com/missing", Version: "v1.0.0"})
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_effectiveRequirements(t *testing.T) {
+	file := parseTestdata(t, "require_modcache/go.mod")
+
+	var mods []module.Version
+	for _, req := range effectiveRequirements(file) {
+		mods = append(mods, req.Mod)
+	}
+
+	expected := []module.Version{
+		{Path: "example.com/Upper", Version: "v1.0.0"},
+		{Path: "example.com/deprecated", Version: "v1.0.0"},
+		{Path: "example.com/fine", Version: "v1.2.0"},
+		{Path: "example.com/missing", Version: "v0.1.0"},
+		{Path: "example.com/fork", Version: "v1.1.0"},
+	}
+
+	assert.Equal(t, expected, mods)
+}
+
+func TestAnalyzeFile_requireDeprecated(t *testing.T) {
+	setupModCache(t)
+
+	file := parseTestdata(t, "require_modcache/go.mod")
+
+	results := AnalyzeFile(file, Options{ReplaceAllowAll: true, RequireCheckDeprecated: true})
+
+	filename := "testdata/require_modcache/go.mod"
+
+	expected := []Result{
+		{
+			Reason: "the required module example.com/deprecated is deprecated: use example.com/fine instead.",
+			Start:  token.Position{Filename: filename, Line: 7, Column: 2},
+			End:    token.Position{Filename: filename, Line: 7, Column: 31},
+		},
+		{
+			Reason:   "the module is not in the module cache, skipped: example.com/missing@v0.1.0",
+			Start:    token.Position{Filename: filename, Line: 10, Column: 2},
+			End:      token.Position{Filename: filename, Line: 10, Column: 28},
+			Severity: SeverityInfo,
+		},
+		{
+			Reason: "the required module example.com/fork is deprecated: the fork is archived.",
+			Start:  token.Position{Filename: filename, Line: 11, Column: 2},
+			End:    token.Position{Filename: filename, Line: 11, Column: 29},
+		},
+	}
+
+	assert.Equal(t, expected, results)
+}
+
+func Test_readLatestCachedGoMod(t *testing.T) {
+	cacheDir := filepath.Join("testdata", "modcache")
+
+	file, version, err := readLatestCachedGoMod(cacheDir, "example.com/retracted")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.5.0", version)
+	assert.Len(t, file.Retract, 2)
+
+	_, _, err = readLatestCachedGoMod(cacheDir, "example.com/missing")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestAnalyzeFile_requireRetracted(t *testing.T) {
+	setupModCache(t)
+
+	file := parseTestdata(t, "require_retracted/go.mod")
+
+	results := AnalyzeFile(file, Options{RequireCheckRetracted: true})
+
+	filename := "testdata/require_retracted/go.mod"
+
+	expected := []Result{
+		{
+			Reason: "the required version example.com/retracted@v1.4.0 is retracted by the module (latest: v1.5.0): CVE-2025-1234",
+			Start:  token.Position{Filename: filename, Line: 7, Column: 2},
+			End:    token.Position{Filename: filename, Line: 7, Column: 30},
+		},
+		{
+			Reason: "the required version example.com/retracted@v1.0.1 is retracted by the module (latest: v1.5.0): no rationale",
+			Start:  token.Position{Filename: filename, Line: 10, Column: 1},
+			End:    token.Position{Filename: filename, Line: 10, Column: 37},
+		},
+	}
+
+	assert.Equal(t, expected, results)
+}
+
+func TestAnalyzeFile_requireGoVersion(t *testing.T) {
+	setupModCache(t)
+
+	file := parseTestdata(t, "require_goversion/go.mod")
+
+	results := AnalyzeFile(file, Options{RequireCheckGoVersion: true})
+
+	filename := "testdata/require_goversion/go.mod"
+
+	expected := []Result{
+		{
+			Reason: "go directive (1.22) is lower than the go directive of the dependency example.com/newgo@v1.0.0 (1.24.1)",
+			Start:  token.Position{Filename: filename, Line: 3, Column: 1},
+			End:    token.Position{Filename: filename, Line: 3, Column: 8},
+		},
+		{
+			Reason: "toolchain directive (go1.23.0) is lower than the toolchain directive of the dependency example.com/newgo@v1.0.0 (go1.24.2)",
+			Start:  token.Position{Filename: filename, Line: 5, Column: 1},
+			End:    token.Position{Filename: filename, Line: 5, Column: 19},
+		},
+	}
+
+	assert.Equal(t, expected, results)
+}
+
+func TestAnalyzeFile_requireGoVersion_valid(t *testing.T) {
+	setupModCache(t)
+
+	file := parseTestdata(t, "require_retracted/go.mod")
+
+	results := AnalyzeFile(file, Options{RequireCheckGoVersion: true})
+
+	assert.Empty(t, results)
+}
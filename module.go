@@ -1,10 +1,15 @@
 package gomoddirectives
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/ldez/grignotin/goenv"
 	"golang.org/x/mod/modfile"
@@ -12,7 +17,12 @@ import (
 
 // GetModuleFile gets module file.
 func GetModuleFile() (*modfile.File, error) {
-	goMod, err := goenv.GetOne(context.Background(), goenv.GOMOD)
+	return GetModuleFileContext(context.Background())
+}
+
+// GetModuleFileContext gets module file.
+func GetModuleFileContext(ctx context.Context) (*modfile.File, error) {
+	goMod, err := goenv.GetOne(ctx, goenv.GOMOD)
 	if err != nil {
 		return nil, err
 	}
@@ -25,11 +35,214 @@ func GetModuleFile() (*modfile.File, error) {
 	return mod, nil
 }
 
+// GetModuleFileFromRev gets the module file at a git revision (branch, tag, commit, etc.).
+// The file is read from the local git object store.
+// Returns nil (without error) if the file doesn't exist at the revision (ex: a new module).
+func GetModuleFileFromRev(ctx context.Context, rev, goMod string) (*modfile.File, error) {
+	dir := filepath.Dir(goMod)
+
+	//nolint:gosec // the revision is provided by the user.
+	cmd := exec.CommandContext(ctx, "git", "show", rev+":./"+filepath.Base(goMod))
+	cmd.Dir = dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	raw, err := cmd.Output()
+	if err != nil {
+		// the revision exists: the file doesn't exist at the revision.
+		if revExists(ctx, dir, rev) {
+			return nil, nil //nolint:nilnil // the file doesn't exist at the revision.
+		}
+
+		return nil, fmt.Errorf("read go.mod at %s: %w: %s", rev, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return modfile.Parse(goMod, raw, nil)
+}
+
+// revExists checks that a git revision exists in the repository of a directory.
+func revExists(ctx context.Context, dir, rev string) bool {
+	//nolint:gosec // the revision is provided by the user.
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	cmd.Dir = dir
+
+	return cmd.Run() == nil
+}
+
+// GetModuleFileFromPath gets the module file from a path.
+func GetModuleFileFromPath(goMod string) (*modfile.File, error) {
+	mod, err := parseGoMod(goMod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod (%s): %w", goMod, err)
+	}
+
+	return mod, nil
+}
+
+// DiscoverModuleFile gets the module file of a directory without calling the go command.
+// The go.mod file is searched in the directory and its parents (the GOFLAGS -modfile flag is honored).
+func DiscoverModuleFile(ctx context.Context, dir string) (*modfile.File, error) {
+	files, err := discoverModuleFiles(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := parseGoMod(files.GoMod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod (%s): %w", files.GoMod, err)
+	}
+
+	return mod, nil
+}
+
 func parseGoMod(goMod string) (*modfile.File, error) {
 	raw, err := os.ReadFile(filepath.Clean(goMod))
 	if err != nil {
 		return nil, fmt.Errorf("reading go.mod file: %w", err)
 	}
 
-	return modfile.Parse("go.mod", raw, nil)
+	return modfile.Parse(goMod, raw, nil)
+}
+
+// moduleDir returns the directory of the module:
+// the directory provided by the options ([Options.WithModuleDir]), or the directory of the module file.
+func moduleDir(file *modfile.File, opts Options) string {
+	if opts.moduleDir != "" {
+		return opts.moduleDir
+	}
+
+	return filepath.Dir(file.Syntax.Name)
+}
+
+// checkIgnoredPath checks that an ignored path exists and doesn't contain Go packages of the module.
+// A path starting with `./` is relative to the module root,
+// otherwise the path matches the directories with this path at any depth of the module.
+func checkIgnoredPath(dir, p string) (string, error) {
+	roots, err := ignoredDirs(dir, p)
+	if err != nil {
+		return "", err
+	}
+
+	if len(roots) == 0 {
+		return reasonIgnoreMissing, nil
+	}
+
+	for _, root := range roots {
+		found, err := containsGoFiles(root)
+		if err != nil {
+			return "", err
+		}
+
+		if found {
+			return reasonIgnoreGoPackages, nil
+		}
+	}
+
+	return "", nil
+}
+
+// ignoredDirs returns the existing directories matched by an ignored path.
+func ignoredDirs(dir, p string) ([]string, error) {
+	if strings.HasPrefix(p, "./") {
+		root := filepath.Join(dir, filepath.FromSlash(p))
+
+		_, err := os.Stat(root)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{root}, nil
+	}
+
+	suffix := strings.Trim(p, "/")
+
+	var roots []string
+
+	err := filepath.WalkDir(dir, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() || current == dir {
+			return nil
+		}
+
+		// nested modules are not part of the module.
+		if isModuleRoot(current) {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(dir, current)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if rel == suffix || strings.HasSuffix(rel, "/"+suffix) {
+			roots = append(roots, current)
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return roots, nil
+}
+
+// containsGoFiles checks if a directory contains Go files of the module.
+func containsGoFiles(root string) (bool, error) {
+	if isModuleRoot(root) {
+		return false, nil
+	}
+
+	errFound := errors.New("found")
+
+	err := filepath.WalkDir(root, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if current == root {
+				return nil
+			}
+
+			// The go command ignores these directories, and nested modules are not part of the module.
+			name := d.Name()
+			if name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || isModuleRoot(current) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasSuffix(d.Name(), ".go") {
+			return errFound
+		}
+
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, errFound):
+		return true, nil
+	case err != nil:
+		return false, err
+	default:
+		return false, nil
+	}
+}
+
+func isModuleRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
 }
@@ -1,6 +1,7 @@
 package gomoddirectives
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,3 +23,13 @@ func TestGetModuleFile_here(t *testing.T) {
 
 	assert.Equal(t, "github.com/ldez/gomoddirectives", file.Module.Mod.Path)
 }
+
+func TestGetModuleFileFromPath(t *testing.T) {
+	goMod := filepath.Join("testdata", "replace", "go.mod")
+
+	file, err := GetModuleFileFromPath(goMod)
+	require.NoError(t, err)
+
+	assert.Equal(t, "github.com/ldez/gomoddirectives/testdata/replace", file.Module.Mod.Path)
+	assert.Equal(t, goMod, file.Syntax.Name)
+}
@@ -0,0 +1,244 @@
+package gomoddirectives
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName the name of the configuration files of the directories.
+const ConfigFileName = ".gomoddirectives.yml"
+
+// Override the configuration of the modules of the directories that match a glob pattern.
+type Override struct {
+	// Pattern the glob pattern of the directories, relative to the directory of the configuration file.
+	// `**` matches any number of directories (ex: `tools/**`).
+	Pattern string
+
+	// raw the configuration (YAML) decoded over the configuration of the parent.
+	raw []byte
+}
+
+// Overrides the overrides of a configuration, in the order of the configuration file.
+type Overrides []Override
+
+// UnmarshalYAML decodes the overrides from a mapping of glob patterns to configurations.
+func (o *Overrides) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: overrides must be a mapping of glob patterns to configurations", value.Line)
+	}
+
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		pattern := value.Content[i].Value
+
+		err := checkGlob(pattern)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", value.Content[i].Line, err)
+		}
+
+		raw, err := yaml.Marshal(value.Content[i+1])
+		if err != nil {
+			return err
+		}
+
+		// validates the configuration (unknown fields, types).
+		err = decodeConfig(raw, &Config{})
+		if err != nil {
+			return fmt.Errorf("overrides %q: %w", pattern, err)
+		}
+
+		*o = append(*o, Override{Pattern: pattern, raw: raw})
+	}
+
+	return nil
+}
+
+// ForDir returns the configuration of the module of a directory:
+// the configuration is overridden by the overrides that match the directory (relative to the root directory),
+// then by the configuration files ([ConfigFileName]) of the directories between the root directory (excluded) and the directory.
+// The root directory is the directory of the configuration file.
+func (c *Config) ForDir(root, dir string) (*Config, error) {
+	rel, err := relDir(root, dir)
+	if err != nil || rel == "" {
+		return c, err
+	}
+
+	cfg, err := c.applyOverrides(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	current := root
+
+	if rel == "." {
+		return cfg, nil
+	}
+
+	for _, segment := range strings.Split(rel, "/") {
+		current = filepath.Join(current, segment)
+
+		filename := filepath.Join(current, ConfigFileName)
+
+		raw, err := os.ReadFile(filepath.Clean(filename))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		cfg, err = mergeConfig(raw, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", filename, err)
+		}
+
+		nested, err := relDir(current, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg, err = cfg.applyOverrides(nested)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// applyOverrides applies the overrides that match a directory (relative, slash separated).
+// The overrides of the returned configuration are resolved (empty).
+func (c *Config) applyOverrides(rel string) (*Config, error) {
+	cfg := c.clone()
+	cfg.Overrides = nil
+
+	for _, override := range c.Overrides {
+		if !matchGlob(override.Pattern, rel) {
+			continue
+		}
+
+		var err error
+
+		cfg, err = mergeConfig(override.raw, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("overrides %q: %w", override.Pattern, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// mergeConfig decodes a YAML configuration over a copy of a configuration.
+// A preset (`preset`) replaces the configuration.
+func mergeConfig(raw []byte, parent *Config) (*Config, error) {
+	preset, err := decodePreset(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := parent.clone()
+	cfg.Overrides = nil
+
+	if preset != "" {
+		cfg, err = Preset(preset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = decodeConfig(raw, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// clone returns a copy of the configuration.
+// The slices are not copied: they are replaced (not modified) by the decoding.
+func (c *Config) clone() *Config {
+	clone := *c
+	clone.RuleSettings = maps.Clone(c.RuleSettings)
+
+	return &clone
+}
+
+// relDir returns the path (slash separated) of a directory relative to a root directory.
+// Returns an empty string if the directory is not inside the root directory.
+func relDir(root, dir string) (string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(absRoot, absDir)
+	if err != nil {
+		return "", nil
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", nil
+	}
+
+	return rel, nil
+}
+
+// checkGlob checks the syntax of a glob pattern.
+func checkGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		_, err := path.Match(segment, "")
+		if err != nil {
+			return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// matchGlob reports whether a path (slash separated) matches a glob pattern.
+// The `**` segments match any number of segments.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,177 @@
+package gomoddirectives
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_matchGlob(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "tools", name: "tools", expected: true},
+		{pattern: "tools", name: "tools/gen", expected: false},
+		{pattern: "tools/*", name: "tools/gen", expected: true},
+		{pattern: "tools/*", name: "tools", expected: false},
+		{pattern: "tools/**", name: "tools", expected: true},
+		{pattern: "tools/**", name: "tools/gen/sub", expected: true},
+		{pattern: "**/examples", name: "a/b/examples", expected: true},
+		{pattern: "**/examples", name: "examples", expected: true},
+		{pattern: "**", name: ".", expected: true},
+		{pattern: "services/*/tools", name: "services/a/tools", expected: true},
+		{pattern: "services/*/tools", name: "services/a/b/tools", expected: false},
+		{pattern: "svc-[ab]", name: "svc-c", expected: false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.pattern+" "+test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, matchGlob(test.pattern, test.name))
+		})
+	}
+}
+
+func TestConfig_ForDir(t *testing.T) {
+	root := t.TempDir()
+
+	cfg, err := LoadConfig(writeConfigFile(t, root, `
+tool-forbidden: true
+replace-allow-list: [example.com/a]
+rule-settings:
+  foo:
+    max: 1
+overrides:
+  tools/**:
+    tool-forbidden: false
+  tools/legacy:
+    preset: application
+  examples/*:
+    replace-local: true
+    rule-settings:
+      foo:
+        max: 2
+`))
+	require.NoError(t, err)
+
+	writeConfigFile(t, filepath.Join(root, "services"), `
+replace-allow-list: [example.com/b]
+overrides:
+  internal:
+    check-format: true
+`)
+
+	testCases := []struct {
+		desc     string
+		dir      string
+		expected *Config
+	}{
+		{
+			desc: "root",
+			dir:  root,
+			expected: &Config{
+				ToolForbidden:    true,
+				ReplaceAllowList: []string{"example.com/a"},
+				RuleSettings:     map[string]any{"foo": map[string]any{"max": 1}},
+			},
+		},
+		{
+			desc: "override",
+			dir:  filepath.Join(root, "tools", "gen"),
+			expected: &Config{
+				ReplaceAllowList: []string{"example.com/a"},
+				RuleSettings:     map[string]any{"foo": map[string]any{"max": 1}},
+			},
+		},
+		{
+			desc: "override with preset",
+			dir:  filepath.Join(root, "tools", "legacy"),
+			expected: &Config{
+				Preset:            PresetApplication,
+				ReplaceAllowLocal: true,
+				ToolchainPattern:  `^go1\.\d+\.\d+$`,
+			},
+		},
+		{
+			desc: "override settings",
+			dir:  filepath.Join(root, "examples", "demo"),
+			expected: &Config{
+				ToolForbidden:     true,
+				ReplaceAllowList:  []string{"example.com/a"},
+				ReplaceAllowLocal: true,
+				RuleSettings:      map[string]any{"foo": map[string]any{"max": 2}},
+			},
+		},
+		{
+			desc: "nested configuration file",
+			dir:  filepath.Join(root, "services", "api"),
+			expected: &Config{
+				ToolForbidden:    true,
+				ReplaceAllowList: []string{"example.com/b"},
+				RuleSettings:     map[string]any{"foo": map[string]any{"max": 1}},
+			},
+		},
+		{
+			desc: "overrides of the nested configuration file",
+			dir:  filepath.Join(root, "services", "internal"),
+			expected: &Config{
+				ToolForbidden:    true,
+				ReplaceAllowList: []string{"example.com/b"},
+				RuleSettings:     map[string]any{"foo": map[string]any{"max": 1}},
+				CheckFormat:      true,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dirCfg, err := cfg.ForDir(root, test.dir)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, dirCfg)
+		})
+	}
+
+	// the configuration of the root is not modified.
+	assert.Equal(t, map[string]any{"foo": map[string]any{"max": 1}}, cfg.RuleSettings)
+	assert.Len(t, cfg.Overrides, 3)
+}
+
+func TestConfig_ForDir_outside(t *testing.T) {
+	root := t.TempDir()
+
+	cfg := &Config{ToolForbidden: true}
+
+	dirCfg, err := cfg.ForDir(filepath.Join(root, "a"), filepath.Join(root, "b"))
+	require.NoError(t, err)
+
+	assert.Equal(t, cfg, dirCfg)
+}
+
+func TestLoadConfig_invalidOverrides(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		content string
+		errMsg  string
+	}{
+		{desc: "not a mapping", content: "overrides: [tools]\n", errMsg: "overrides must be a mapping"},
+		{desc: "invalid glob", content: "overrides:\n  'tools/[':\n    tool-forbidden: false\n", errMsg: "invalid glob pattern"},
+		{desc: "unknown field", content: "overrides:\n  tools:\n    tool-forbiden: false\n", errMsg: "tool-forbiden"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := LoadConfig(writeConfigFile(t, t.TempDir(), test.content))
+			require.ErrorContains(t, err, test.errMsg)
+		})
+	}
+}
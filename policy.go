@@ -0,0 +1,277 @@
+package gomoddirectives
+
+import (
+	"fmt"
+	"go/token"
+	"go/version"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// PolicyDirectives the kinds of directives supported by the policies.
+var PolicyDirectives = []string{
+	"module",
+	"go",
+	"toolchain",
+	"godebug",
+	"require",
+	"replace",
+	"exclude",
+	"retract",
+	"tool",
+	"ignore",
+}
+
+// Policy a declarative rule: a directive that matches all the matchers of the policy is reported.
+// The empty matchers match all the directives.
+type Policy struct {
+	// Name the name of the policy.
+	Name string
+	// Directives the kinds of directives (see [PolicyDirectives]).
+	Directives []string
+	// Path the pattern of the path of the directive (module path, tool package, ignored path, godebug key).
+	Path *regexp.Regexp
+	// Replacement the pattern of the replacement path (replace directives only).
+	Replacement *regexp.Regexp
+	// Version the version constraint: comma separated comparisons (ex: ">=v1.5.0", ">=v1.2.0,<v2.0.0").
+	// The version of a replace directive is the version of the replacement.
+	Version string
+	// Comment the pattern of the comments of the directive (without the `//`).
+	Comment *regexp.Regexp
+	// Message the reason of the results.
+	Message string
+	// Severity the severity of the results.
+	Severity Severity
+}
+
+// policyTarget the values of a directive matched by the policies.
+type policyTarget struct {
+	Kind        string
+	Path        string
+	Replacement string
+	Version     string
+	Line        *modfile.Line
+}
+
+// versionComparison a comparison of a version constraint.
+type versionComparison struct {
+	Op      string
+	Version string
+}
+
+func checkPolicies(file *modfile.File, opts Options) []Result {
+	if len(opts.Policies) == 0 {
+		return nil
+	}
+
+	targets := policyTargets(file)
+
+	var results []Result
+
+	for _, policy := range opts.Policies {
+		constraint, err := parseVersionConstraint(policy.Version)
+		if err != nil {
+			results = append(results, Result{
+				Reason: fmt.Sprintf("policy %s: %v", policy.Name, err),
+				Start:  token.Position{Filename: file.Syntax.Name, Line: 1, Column: 1},
+				End:    token.Position{Filename: file.Syntax.Name, Line: 1, Column: 1},
+			})
+
+			continue
+		}
+
+		for _, target := range targets {
+			if !policy.match(target, constraint) {
+				continue
+			}
+
+			reason := policy.Message
+			if reason == "" {
+				reason = fmt.Sprintf(reasonPolicy, target.Kind, policy.Name)
+			}
+
+			result := NewResult(file, target.Line, reason)
+			result.Severity = policy.Severity
+
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+func (p Policy) match(target policyTarget, constraint []versionComparison) bool {
+	if len(p.Directives) > 0 && !slices.Contains(p.Directives, target.Kind) {
+		return false
+	}
+
+	if p.Path != nil && (target.Path == "" || !p.Path.MatchString(target.Path)) {
+		return false
+	}
+
+	if p.Replacement != nil && (target.Replacement == "" || !p.Replacement.MatchString(target.Replacement)) {
+		return false
+	}
+
+	if p.Comment != nil && !p.Comment.MatchString(lineComments(target.Line)) {
+		return false
+	}
+
+	if len(constraint) > 0 && (target.Version == "" || !matchVersion(target.Kind, target.Version, constraint)) {
+		return false
+	}
+
+	return true
+}
+
+// policyTargets returns the directives of the module file that can be matched by the policies.
+func policyTargets(file *modfile.File) []policyTarget {
+	var targets []policyTarget
+
+	if file.Module != nil {
+		targets = append(targets, policyTarget{Kind: "module", Path: file.Module.Mod.Path, Line: file.Module.Syntax})
+	}
+
+	if file.Go != nil {
+		targets = append(targets, policyTarget{Kind: "go", Version: file.Go.Version, Line: file.Go.Syntax})
+	}
+
+	if file.Toolchain != nil {
+		targets = append(targets, policyTarget{Kind: "toolchain", Version: file.Toolchain.Name, Line: file.Toolchain.Syntax})
+	}
+
+	for _, d := range file.Godebug {
+		targets = append(targets, policyTarget{Kind: "godebug", Path: d.Key, Line: d.Syntax})
+	}
+
+	for _, r := range file.Require {
+		targets = append(targets, policyTarget{Kind: "require", Path: r.Mod.Path, Version: r.Mod.Version, Line: r.Syntax})
+	}
+
+	for _, r := range file.Replace {
+		targets = append(targets, policyTarget{Kind: "replace", Path: r.Old.Path, Replacement: r.New.Path, Version: r.New.Version, Line: r.Syntax})
+	}
+
+	for _, e := range file.Exclude {
+		targets = append(targets, policyTarget{Kind: "exclude", Path: e.Mod.Path, Version: e.Mod.Version, Line: e.Syntax})
+	}
+
+	for _, r := range file.Retract {
+		targets = append(targets, policyTarget{Kind: "retract", Version: r.Low, Line: r.Syntax})
+	}
+
+	for _, t := range file.Tool {
+		targets = append(targets, policyTarget{Kind: "tool", Path: t.Path, Line: t.Syntax})
+	}
+
+	for _, i := range file.Ignore {
+		targets = append(targets, policyTarget{Kind: "ignore", Path: i.Path, Line: i.Syntax})
+	}
+
+	return targets
+}
+
+// lineComments returns the comments of a line (before and suffix), without the `//`.
+func lineComments(line *modfile.Line) string {
+	var comments []string
+
+	for _, c := range slices.Concat(line.Before, line.Suffix) {
+		comments = append(comments, strings.TrimSpace(strings.TrimPrefix(c.Token, "//")))
+	}
+
+	return strings.Join(comments, "\n")
+}
+
+// parseVersionConstraint parses a version constraint (ex: ">=v1.5.0,<v2.0.0").
+// The versions are semantic versions (ex: v1.5.0), or Go versions (ex: 1.22, go1.22.0) for the go and toolchain directives.
+func parseVersionConstraint(constraint string) ([]versionComparison, error) {
+	if strings.TrimSpace(constraint) == "" {
+		return nil, nil
+	}
+
+	var comparisons []versionComparison
+
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+
+		var c versionComparison
+
+		for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+			if v, ok := strings.CutPrefix(part, op); ok {
+				c = versionComparison{Op: op, Version: strings.TrimSpace(v)}
+				break
+			}
+		}
+
+		if c.Op == "" {
+			c = versionComparison{Op: "=", Version: part}
+		}
+
+		if !semver.IsValid(c.Version) && !version.IsValid(goVersionName(c.Version)) {
+			return nil, fmt.Errorf("invalid version constraint %q", part)
+		}
+
+		comparisons = append(comparisons, c)
+	}
+
+	return comparisons, nil
+}
+
+// matchVersion checks that a version satisfies all the comparisons of a version constraint.
+func matchVersion(kind, v string, constraint []versionComparison) bool {
+	for _, c := range constraint {
+		var diff int
+
+		switch kind {
+		case "go", "toolchain":
+			if !version.IsValid(goVersionName(v)) || !version.IsValid(goVersionName(c.Version)) {
+				return false
+			}
+
+			diff = version.Compare(goVersionName(v), goVersionName(c.Version))
+
+		default:
+			if !semver.IsValid(v) || !semver.IsValid(c.Version) {
+				return false
+			}
+
+			diff = semver.Compare(v, c.Version)
+		}
+
+		var ok bool
+
+		switch c.Op {
+		case ">=":
+			ok = diff >= 0
+		case "<=":
+			ok = diff <= 0
+		case ">":
+			ok = diff > 0
+		case "<":
+			ok = diff < 0
+		case "!=":
+			ok = diff != 0
+		default:
+			ok = diff == 0
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// goVersionName returns the name of a Go version (ex: 1.22 => go1.22).
+func goVersionName(v string) string {
+	if strings.HasPrefix(v, "go") {
+		return v
+	}
+
+	return "go" + v
+}
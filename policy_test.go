@@ -0,0 +1,117 @@
+package gomoddirectives
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+
+const policyGoMod = `module example.com/foo
+
+go 1.22
+
+toolchain go1.23.4
+
+require (
+	github.com/a/a v1.2.0
+	github.com/b/b v1.6.0
+	github.com/c/c v0.1.0 // TODO remove
+)
+
+replace (
+	github.com/a/a => github.com/fork/a v1.2.1
+	github.com/b/b => ../b
+)
+`
+
+func Test_checkPolicies(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		policy   Policy
+		expected []string
+	}{
+		{
+			desc:     "directive",
+			policy:   Policy{Name: "no-replace", Directives: []string{"replace"}},
+			expected: []string{"14: error: replace directive is forbidden by the policy \"no-replace\"", "15: error: replace directive is forbidden by the policy \"no-replace\""},
+		},
+		{
+			desc: "path and replacement",
+			policy: Policy{
+				Directives:  []string{"replace"},
+				Path:        regexp.MustCompile(`^github\.com/a/`),
+				Replacement: regexp.MustCompile(`^github\.com/fork/`),
+				Message:     "forks are not allowed",
+				Severity:    SeverityWarning,
+			},
+			expected: []string{"14: warning: forks are not allowed"},
+		},
+		{
+			desc: "version constraint",
+			policy: Policy{
+				Directives: []string{"require"},
+				Path:       regexp.MustCompile(`^github\.com/[ab]/`),
+				Version:    "<v1.5.0",
+				Message:    "must be >= v1.5.0",
+			},
+			expected: []string{"8: error: must be >= v1.5.0"},
+		},
+		{
+			desc:     "version range",
+			policy:   Policy{Directives: []string{"require"}, Version: ">=v0.1.0,<v1.3.0", Message: "range"},
+			expected: []string{"8: error: range", "10: error: range"},
+		},
+		{
+			desc:     "go version",
+			policy:   Policy{Directives: []string{"go", "toolchain"}, Version: "<1.23", Message: "too old"},
+			expected: []string{"3: error: too old"},
+		},
+		{
+			desc:     "toolchain version",
+			policy:   Policy{Directives: []string{"toolchain"}, Version: ">=go1.23.0", Message: "new toolchain", Severity: SeverityInfo},
+			expected: []string{"5: info: new toolchain"},
+		},
+		{
+			desc:     "comment",
+			policy:   Policy{Comment: regexp.MustCompile(`^TODO`), Message: "TODO"},
+			expected: []string{"10: error: TODO"},
+		},
+		{
+			desc:     "no version",
+			policy:   Policy{Directives: []string{"replace"}, Version: ">=v0.0.0", Message: "version"},
+			expected: []string{"14: error: version"},
+		},
+		{
+			desc:     "no match",
+			policy:   Policy{Directives: []string{"exclude"}},
+			expected: nil,
+		},
+		{
+			desc:     "invalid version constraint",
+			policy:   Policy{Name: "invalid", Version: ">=foo"},
+			expected: []string{"1: error: policy invalid: invalid version constraint \">=foo\""},
+		},
+	}
+
+	file, err := modfile.Parse("go.mod", []byte(policyGoMod), nil)
+	require.NoError(t, err)
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			results := checkPolicies(file, Options{Policies: []Policy{test.policy}})
+
+			var actual []string
+			for _, r := range results {
+				actual = append(actual, fmt.Sprintf("%d: %s: %s", r.Start.Line, r.Severity, r.Reason))
+			}
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package gomoddirectives
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Preset names.
+const (
+	PresetLibrary     = "library"
+	PresetApplication = "application"
+	PresetStrict      = "strict"
+)
+
+// presets the configurations of the presets.
+var presets = map[string]Config{
+	// library: the replace directives are ignored by the users of the module,
+	// the retracted versions must be explained, and the module path must be valid.
+	PresetLibrary: {
+		CheckModulePath:      true,
+		ModulePathCheckMajor: true,
+	},
+	// application: the local replacements are allowed (ex: local forks), and the toolchain must be pinned to a release.
+	PresetApplication: {
+		ReplaceAllowLocal: true,
+		ToolchainPattern:  `^go1\.\d+\.\d+$`,
+	},
+	// strict: all the checks are enabled, and the optional directives are forbidden.
+	// The toolchain directive is not forbidden: the go command adds it when the go directive is updated.
+	PresetStrict: {
+		ExcludeForbidden:       true,
+		ExcludeCheckRequire:    true,
+		IgnoreForbidden:        true,
+		IgnoreCheckPaths:       true,
+		RetractCheckRanges:     true,
+		RetractCheckRelease:    true,
+		RetractCheckTags:       true,
+		ToolForbidden:          true,
+		ToolCheckRequire:       true,
+		GoDebugForbidden:       true,
+		DeprecatedForbidden:    true,
+		CheckModulePath:        true,
+		ModulePathCheckVCS:     true,
+		ModulePathCheckMajor:   true,
+		RequireCheckDeprecated: true,
+		RequireCheckRetracted:  true,
+		RequireCheckGoVersion:  true,
+		RequireCheckBlocks:     true,
+		CheckGoSum:             true,
+		CheckVendor:            true,
+		CheckFormat:            true,
+		CheckLayout:            true,
+	},
+}
+
+// PresetNames returns the names of the presets.
+func PresetNames() []string {
+	return slices.Sorted(maps.Keys(presets))
+}
+
+// Preset returns the configuration of a preset.
+func Preset(name string) (*Config, error) {
+	preset, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q (available: %v)", name, PresetNames())
+	}
+
+	preset.Preset = name
+
+	return &preset, nil
+}
@@ -0,0 +1,78 @@
+package gomoddirectives
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreset(t *testing.T) {
+	assert.Equal(t, []string{PresetApplication, PresetLibrary, PresetStrict}, PresetNames())
+
+	for _, name := range PresetNames() {
+		cfg, err := Preset(name)
+		require.NoError(t, err)
+
+		assert.Equal(t, name, cfg.Preset)
+
+		_, err = cfg.Options()
+		require.NoError(t, err)
+	}
+
+	_, err := Preset("foo")
+	require.Error(t, err)
+}
+
+func TestPreset_copy(t *testing.T) {
+	cfg, err := Preset(PresetStrict)
+	require.NoError(t, err)
+
+	cfg.CheckFormat = false
+
+	cfg, err = Preset(PresetStrict)
+	require.NoError(t, err)
+
+	assert.True(t, cfg.CheckFormat)
+}
+
+func TestLoadConfig_preset(t *testing.T) {
+	filename := writeConfigFile(t, t.TempDir(), `
+preset: application
+replace-local: false
+check-format: true
+`)
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+
+	expected := &Config{
+		Preset:           PresetApplication,
+		ToolchainPattern: `^go1\.\d+\.\d+$`,
+		CheckFormat:      true,
+	}
+
+	assert.Equal(t, expected, cfg)
+}
+
+func TestLoadConfigWithPreset(t *testing.T) {
+	filename := writeConfigFile(t, t.TempDir(), `
+preset: application
+check-module-path: false
+`)
+
+	cfg, err := LoadConfigWithPreset(filename, PresetLibrary)
+	require.NoError(t, err)
+
+	expected := &Config{
+		Preset:               PresetLibrary,
+		ModulePathCheckMajor: true,
+	}
+
+	assert.Equal(t, expected, cfg)
+}
+
+func TestLoadConfig_unknownPreset(t *testing.T) {
+	_, err := LoadConfig(writeConfigFile(t, t.TempDir(), "preset: foo\n"))
+	require.ErrorContains(t, err, `unknown preset "foo"`)
+}
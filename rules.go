@@ -0,0 +1,118 @@
+package gomoddirectives
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Rule a rule that checks a module file.
+type Rule interface {
+	// ID the unique identifier of the rule.
+	ID() string
+	// Description a short description of the rule.
+	Description() string
+	// Check checks a module file.
+	Check(file *modfile.File, opts Options) []Result
+}
+
+// checkRule a rule based on a check function.
+type checkRule struct {
+	id          string
+	description string
+	check       func(file *modfile.File, opts Options) []Result
+}
+
+func (r checkRule) ID() string {
+	return r.id
+}
+
+func (r checkRule) Description() string {
+	return r.description
+}
+
+func (r checkRule) Check(file *modfile.File, opts Options) []Result {
+	return r.check(file, opts)
+}
+
+// builtinRules the rules provided by gomoddirectives, in the order of execution.
+var builtinRules = []Rule{
+	checkRule{id: "module-path", description: "Checks the module path", check: checkModulePath},
+	checkRule{id: "module-deprecation", description: "Checks the deprecation of the module", check: checkModuleDeprecation},
+	checkRule{id: "retract", description: "Checks the retract directives", check: checkRetractDirectives},
+	checkRule{id: "exclude", description: "Checks the exclude directives", check: checkExcludeDirectives},
+	checkRule{id: "tool", description: "Checks the tool directives", check: checkToolDirectives},
+	checkRule{id: "ignore", description: "Checks the ignore directives", check: checkIgnoreDirectives},
+	checkRule{id: "replace", description: "Checks the replace directives", check: checkReplaceDirectives},
+	checkRule{id: "require", description: "Checks the require directives against the module cache", check: checkRequireDirectives},
+	checkRule{id: "toolchain", description: "Checks the toolchain directive", check: checkToolchainDirective},
+	checkRule{id: "godebug", description: "Checks the godebug directives", check: checkGoDebugDirectives},
+	checkRule{id: "go-version", description: "Checks the go directive", check: checkGoVersionDirectives},
+	checkRule{id: "go-sum", description: "Checks the consistency of the go.sum file", check: checkGoSum},
+	checkRule{id: "vendor", description: "Checks the consistency of the vendor/modules.txt file", check: checkVendor},
+	checkRule{id: "policy", description: "Checks the declarative policies", check: checkPolicies},
+	checkRule{id: "layout", description: "Checks the order of the directives and the use of blocks", check: checkLayout},
+	checkRule{id: "require-blocks", description: "Checks the separation of the direct and indirect requirements", check: checkRequireBlocks},
+	checkRule{id: "format", description: "Checks the canonical formatting of the module file", check: checkFormat},
+}
+
+// registry the rules registered by the users.
+var registry struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// RegisterRule registers a custom rule.
+// The registered rules are executed by [AnalyzeFile] after the built-in rules.
+// Returns an error if a rule with the same ID is already registered.
+func RegisterRule(rule Rule) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	id := rule.ID()
+
+	exists := func(r Rule) bool { return r.ID() == id }
+
+	if id == "" || slices.ContainsFunc(builtinRules, exists) || slices.ContainsFunc(registry.rules, exists) {
+		return fmt.Errorf("invalid rule ID or rule already registered: %q", id)
+	}
+
+	registry.rules = append(registry.rules, rule)
+
+	return nil
+}
+
+// Rules returns the built-in rules and the registered rules, in the order of execution.
+func Rules() []Rule {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return slices.Concat(builtinRules, registry.rules)
+}
+
+// DecodeRuleSettings decodes the settings of a rule ([Options.RuleSettings]) into a typed value.
+// The settings are decoded as JSON: the fields of the type must use `json` tags.
+// Returns the zero value if the rule has no settings.
+func DecodeRuleSettings[T any](opts Options, id string) (T, error) {
+	var settings T
+
+	raw, ok := opts.RuleSettings[id]
+	if !ok || raw == nil {
+		return settings, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return settings, fmt.Errorf("encode the settings of the rule %s: %w", id, err)
+	}
+
+	err = json.Unmarshal(data, &settings)
+	if err != nil {
+		return settings, fmt.Errorf("decode the settings of the rule %s: %w", id, err)
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,94 @@
+package gomoddirectives
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+
+type maxRequireSettings struct {
+	Max int `json:"max"`
+}
+
+// maxRequireRule reports the modules with too many requirements.
+type maxRequireRule struct{}
+
+func (maxRequireRule) ID() string {
+	return "test-max-require"
+}
+
+func (maxRequireRule) Description() string {
+	return "Limits the number of requirements"
+}
+
+func (r maxRequireRule) Check(file *modfile.File, opts Options) []Result {
+	settings, err := DecodeRuleSettings[*maxRequireSettings](opts, r.ID())
+	if err != nil || settings == nil {
+		return nil
+	}
+
+	if len(file.Require) <= settings.Max {
+		return nil
+	}
+
+	return []Result{NewResult(file, file.Module.Syntax, "too many requirements")}
+}
+
+// unregisterRule removes a registered rule.
+func unregisterRule(id string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.rules = slices.DeleteFunc(registry.rules, func(r Rule) bool { return r.ID() == id })
+}
+
+func TestRegisterRule(t *testing.T) {
+	require.NoError(t, RegisterRule(maxRequireRule{}))
+
+	t.Cleanup(func() { unregisterRule(maxRequireRule{}.ID()) })
+
+	err := RegisterRule(maxRequireRule{})
+	require.Error(t, err)
+
+	assert.Contains(t, Rules(), Rule(maxRequireRule{}))
+
+	file := writeGoMod(t, t.TempDir(), formattedGoMod)
+
+	results := AnalyzeFile(file, Options{})
+	assert.Empty(t, results)
+
+	results = AnalyzeFile(file, Options{RuleSettings: map[string]any{"test-max-require": map[string]any{"max": 1}}})
+	require.Len(t, results, 1)
+
+	assert.Equal(t, "too many requirements", results[0].Reason)
+}
+
+func TestRegisterRule_builtin(t *testing.T) {
+	err := RegisterRule(checkRule{id: "replace"})
+	require.Error(t, err)
+
+	err = RegisterRule(checkRule{})
+	require.Error(t, err)
+}
+
+func TestDecodeRuleSettings(t *testing.T) {
+	opts := Options{RuleSettings: map[string]any{"foo": map[string]any{"max": 3}}}
+
+	settings, err := DecodeRuleSettings[maxRequireSettings](opts, "foo")
+	require.NoError(t, err)
+
+	assert.Equal(t, maxRequireSettings{Max: 3}, settings)
+
+	settings, err = DecodeRuleSettings[maxRequireSettings](opts, "bar")
+	require.NoError(t, err)
+
+	assert.Zero(t, settings)
+
+	opts = Options{RuleSettings: map[string]any{"foo": map[string]any{"max": "a"}}}
+
+	_, err = DecodeRuleSettings[maxRequireSettings](opts, "foo")
+	require.Error(t, err)
+}
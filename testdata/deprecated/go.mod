@@ -0,0 +1,4 @@
+// Deprecated: use github.com/ldez/gomoddirectives/testdata/v2 instead.
+module github.com/ldez/gomoddirectives/testdata/deprecated
+
+go 1.22
@@ -0,0 +1,4 @@
+// Deprecated: use FOO instead.
+module github.com/ldez/gomoddirectives/testdata/deprecated_invalid
+
+go 1.22
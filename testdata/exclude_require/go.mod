@@ -0,0 +1,15 @@
+module github.com/ldez/gomoddirectives/testdata/exclude_require
+
+go 1.22
+
+require (
+	github.com/gorilla/mux v1.7.3
+	github.com/ldez/grignotin v0.4.1
+)
+
+exclude (
+	github.com/gorilla/mux v1.7.0
+	github.com/gorilla/mux v1.7.0
+	github.com/ldez/grignotin v0.4.1
+	golang.org/x/text v0.3.0
+)
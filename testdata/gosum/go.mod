@@ -0,0 +1,11 @@
+module github.com/ldez/gomoddirectives/testdata/gosum
+
+go 1.22
+
+require (
+	example.com/fine v1.2.0
+	example.com/local v1.0.0
+	example.com/newgo v1.0.0
+)
+
+replace example.com/local => ./local
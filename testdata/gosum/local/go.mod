@@ -0,0 +1,5 @@
+module example.com/local
+
+go 1.22
+
+require example.com/retracted v1.5.0
@@ -0,0 +1,14 @@
+module github.com/ldez/gomoddirectives/testdata/ignore_paths
+
+go 1.25
+
+ignore (
+	./node_modules
+	./third_party/js
+	./internal
+	./tools
+	./missing
+	bower_components
+	api/generated
+	dist
+)
@@ -0,0 +1,3 @@
+module github.com/ldez/gomoddirectives/testdata/ignore_paths/tools
+
+go 1.25
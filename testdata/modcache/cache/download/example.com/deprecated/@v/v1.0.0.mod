@@ -0,0 +1,4 @@
+// Deprecated: use example.com/fine instead.
+module example.com/deprecated
+
+go 1.21
@@ -0,0 +1,4 @@
+// Deprecated: the fork is archived.
+module example.com/fork
+
+go 1.20
@@ -0,0 +1,7 @@
+module example.com/newgo
+
+go 1.24.1
+
+toolchain go1.24.2
+
+require example.com/Upper v1.0.0
@@ -0,0 +1,8 @@
+module example.com/retracted
+
+go 1.21
+
+retract (
+	v1.4.0 // CVE-2025-1234
+	[v1.0.0, v1.1.0]
+)
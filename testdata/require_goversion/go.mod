@@ -0,0 +1,11 @@
+module github.com/ldez/gomoddirectives/testdata/require_goversion
+
+go 1.22
+
+toolchain go1.23.0
+
+require (
+	example.com/fine v1.2.0
+	example.com/newgo v1.0.0
+	example.com/retracted v1.4.0
+)
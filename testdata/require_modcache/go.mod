@@ -0,0 +1,17 @@
+module github.com/ldez/gomoddirectives/testdata/require_modcache
+
+go 1.22
+
+require (
+	example.com/Upper v1.0.0
+	example.com/deprecated v1.0.0
+	example.com/fine v1.2.0
+	example.com/local v1.0.0
+	example.com/missing v0.1.0
+	example.com/replaced v1.0.0
+)
+
+replace (
+	example.com/local => ../local
+	example.com/replaced => example.com/fork v1.1.0
+)
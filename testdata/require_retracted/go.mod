@@ -0,0 +1,10 @@
+module github.com/ldez/gomoddirectives/testdata/require_retracted
+
+go 1.22
+
+require (
+	example.com/fine v1.2.0
+	example.com/retracted v1.4.0
+)
+
+require example.com/retracted v1.0.1
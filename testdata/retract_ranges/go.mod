@@ -0,0 +1,11 @@
+module github.com/ldez/gomoddirectives/testdata/retract_ranges
+
+go 1.22
+
+retract (
+	v1.0.0 // CVE-2024-0001
+	[v1.2.0, v1.1.0] // CVE-2024-0002
+	v1.0.0 // CVE-2024-0001
+	[v1.3.0, v1.5.0] // CVE-2024-0003
+	v1.4.0 // published by mistake
+)
@@ -0,0 +1,12 @@
+module github.com/ldez/gomoddirectives/testdata/tool_consistency
+
+go 1.24
+
+require golang.org/x/tools v0.27.0
+
+tool (
+	golang.org/x/tools/cmd/stringer
+	github.com/ldez/gomoddirectives/testdata/tool_consistency/cmd/gen
+	example.com/module/cmd/a
+	golang.org/x/tools/cmd/stringer
+)
@@ -0,0 +1,18 @@
+module github.com/ldez/gomoddirectives/testdata/vendor_check
+
+go 1.22
+
+require (
+	example.com/fine v1.2.0
+	example.com/newgo v1.0.0
+	example.com/deprecated v1.0.0
+	example.com/retracted v1.5.0
+	example.com/local v1.0.0
+	example.com/replaced v1.0.0
+)
+
+replace (
+	example.com/local => ../local
+	example.com/replaced => example.com/fork v1.1.0
+	example.com/wild => ../wild
+)
@@ -0,0 +1,294 @@
+package gomoddirectives
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// vendorMeta the metadata of a module inside the vendor/modules.txt file.
+type vendorMeta struct {
+	Explicit    bool
+	GoVersion   string
+	Replacement module.Version
+}
+
+// vendorModules the content of a vendor/modules.txt file.
+type vendorModules struct {
+	// Mods the vendored modules, in the order of the file.
+	Mods []module.Version
+	Meta map[module.Version]*vendorMeta
+	// Replaced the replacements of all the versions of a module (`# old => new`).
+	Replaced map[string]module.Version
+}
+
+// parseVendorModules parses a vendor/modules.txt file.
+// https://github.com/golang/go/blob/master/src/cmd/go/internal/modload/vendor.go
+func parseVendorModules(filename string) (*vendorModules, error) {
+	f, err := os.Open(filepath.Clean(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	vendor := &vendorModules{
+		Meta:     map[module.Version]*vendorMeta{},
+		Replaced: map[string]module.Version{},
+	}
+
+	var current *vendorMeta
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if current == nil {
+				continue
+			}
+
+			for entry := range strings.SplitSeq(strings.TrimPrefix(line, "## "), ";") {
+				entry = strings.TrimSpace(entry)
+
+				switch {
+				case entry == "explicit":
+					current.Explicit = true
+				case strings.HasPrefix(entry, "go "):
+					current.GoVersion = strings.TrimPrefix(entry, "go ")
+				}
+			}
+
+		case strings.HasPrefix(line, "# "):
+			current = vendor.add(strings.Fields(strings.TrimPrefix(line, "# ")))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", filename, err)
+	}
+
+	return vendor, nil
+}
+
+// add adds a module line (`# path version [=> path [version]]` or `# path => path [version]`).
+func (v *vendorModules) add(fields []string) *vendorMeta {
+	switch {
+	case len(fields) >= 2 && fields[1] == "=>":
+		v.Replaced[fields[0]] = replacementVersion(fields[2:])
+
+		return nil
+
+	case len(fields) >= 2:
+		mod := module.Version{Path: fields[0], Version: fields[1]}
+
+		meta := &vendorMeta{}
+
+		if len(fields) > 3 && fields[2] == "=>" {
+			meta.Replacement = replacementVersion(fields[3:])
+		}
+
+		v.Mods = append(v.Mods, mod)
+		v.Meta[mod] = meta
+
+		return meta
+
+	default:
+		return nil
+	}
+}
+
+func replacementVersion(fields []string) module.Version {
+	switch len(fields) {
+	case 0:
+		return module.Version{}
+	case 1:
+		return module.Version{Path: fields[0]}
+	default:
+		return module.Version{Path: fields[0], Version: fields[1]}
+	}
+}
+
+// replacement returns the replacement of a module version (or of all the versions if the version is empty).
+func (v *vendorModules) replacement(old module.Version) module.Version {
+	if r, ok := v.Replaced[old.Path]; ok && old.Version == "" {
+		return r
+	}
+
+	for _, mod := range v.Mods {
+		if mod.Path != old.Path || (old.Version != "" && mod.Version != old.Version) {
+			continue
+		}
+
+		if r := v.Meta[mod].Replacement; r.Path != "" {
+			return r
+		}
+	}
+
+	return module.Version{}
+}
+
+// checkVendor checks the consistency of the vendor/modules.txt file located next to the module file.
+func checkVendor(file *modfile.File, opts Options) []Result {
+	if !opts.CheckVendor || file.Module == nil {
+		return nil
+	}
+
+	vendor, err := parseVendorModules(filepath.Join(moduleDir(file, opts), "vendor", "modules.txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return []Result{NewResult(file, file.Module.Syntax, err.Error())}
+	}
+
+	var results []Result
+
+	results = append(results, checkVendorRequire(file, vendor)...)
+	results = append(results, checkVendorReplace(file, vendor)...)
+
+	return results
+}
+
+func checkVendorRequire(file *modfile.File, vendor *vendorModules) []Result {
+	var results []Result
+
+	required := map[module.Version]bool{}
+
+	cacheDir := modCacheDir()
+
+	for _, req := range file.Require {
+		required[req.Mod] = true
+
+		meta, ok := vendor.Meta[req.Mod]
+
+		switch {
+		case !ok && vendor.hasModule(req.Mod.Path):
+			reason := fmt.Sprintf(reasonVendorVersion, req.Mod.Path, req.Mod.Version, vendor.version(req.Mod.Path))
+			results = append(results, NewResult(file, req.Syntax, reason))
+
+		case !ok || !meta.Explicit:
+			results = append(results, NewResult(file, req.Syntax, fmt.Sprintf(reasonVendorExplicit, req.Mod)))
+
+		default:
+			if reason := checkVendorGoVersion(file, cacheDir, req, meta); reason != "" {
+				results = append(results, NewResult(file, req.Syntax, reason))
+			}
+		}
+	}
+
+	for _, mod := range vendor.Mods {
+		// a version mismatch is already reported on the require directive.
+		if vendor.Meta[mod].Explicit && !required[mod] && !isRequired(file, mod.Path) {
+			results = append(results, NewResult(file, file.Module.Syntax, fmt.Sprintf(reasonVendorNotRequired, mod)))
+		}
+	}
+
+	return results
+}
+
+// checkVendorGoVersion compares the go version annotation with the go directive of the dependency (read from the module cache).
+func checkVendorGoVersion(file *modfile.File, cacheDir string, req *modfile.Require, meta *vendorMeta) string {
+	mod := req.Mod
+
+	if replace := findReplace(file, req.Mod); replace != nil {
+		if isLocal(replace) {
+			return ""
+		}
+
+		mod = replace.New
+	}
+
+	dep, err := readCachedGoMod(cacheDir, mod)
+	if err != nil {
+		return ""
+	}
+
+	var goVersion string
+	if dep.Go != nil {
+		goVersion = dep.Go.Version
+	}
+
+	if goVersion == meta.GoVersion {
+		return ""
+	}
+
+	return fmt.Sprintf(reasonVendorGoVersion, req.Mod, meta.GoVersion, goVersion)
+}
+
+func checkVendorReplace(file *modfile.File, vendor *vendorModules) []Result {
+	var results []Result
+
+	replaced := map[module.Version]bool{}
+
+	for _, replace := range file.Replace {
+		replaced[replace.Old] = true
+
+		vr := vendor.replacement(replace.Old)
+
+		switch {
+		case vr == module.Version{}:
+			results = append(results, NewResult(file, replace.Syntax, fmt.Sprintf(reasonVendorNotReplaced, describeVersion(replace.Old))))
+
+		case vr != replace.New:
+			reason := fmt.Sprintf(reasonVendorReplacement, describeVersion(replace.Old), describeVersion(replace.New), describeVersion(vr))
+			results = append(results, NewResult(file, replace.Syntax, reason))
+		}
+	}
+
+	for _, mod := range vendor.Mods {
+		if vendor.Meta[mod].Replacement.Path != "" && !replaced[mod] && !replaced[module.Version{Path: mod.Path}] {
+			results = append(results, NewResult(file, file.Module.Syntax, fmt.Sprintf(reasonVendorReplaced, mod)))
+		}
+	}
+
+	for _, old := range slices.Sorted(maps.Keys(vendor.Replaced)) {
+		if !replaced[module.Version{Path: old}] {
+			results = append(results, NewResult(file, file.Module.Syntax, fmt.Sprintf(reasonVendorReplaced, old)))
+		}
+	}
+
+	return results
+}
+
+// hasModule checks if a version of the module is vendored.
+func (v *vendorModules) hasModule(modPath string) bool {
+	return v.version(modPath) != ""
+}
+
+// version returns the vendored version of a module.
+func (v *vendorModules) version(modPath string) string {
+	for _, mod := range v.Mods {
+		if mod.Path == modPath {
+			return mod.Version
+		}
+	}
+
+	return ""
+}
+
+// isRequired checks if a version of the module is required.
+func isRequired(file *modfile.File, modPath string) bool {
+	return slices.ContainsFunc(file.Require, func(r *modfile.Require) bool {
+		return r.Mod.Path == modPath
+	})
+}
+
+func describeVersion(mod module.Version) string {
+	if mod.Version == "" {
+		return mod.Path
+	}
+
+	return mod.String()
+}
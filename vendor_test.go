@@ -0,0 +1,60 @@
+package gomoddirectives
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+)
+
+func Test_parseVendorModules(t *testing.T) {
+	vendor, err := parseVendorModules(filepath.Join("testdata", "vendor_check", "vendor", "modules.txt"))
+	require.NoError(t, err)
+
+	assert.Len(t, vendor.Mods, 7)
+
+	assert.Equal(t, &vendorMeta{Explicit: true, GoVersion: "1.20"}, vendor.Meta[module.Version{Path: "example.com/fine", Version: "v1.2.0"}])
+	assert.Equal(t, &vendorMeta{GoVersion: "1.21"}, vendor.Meta[module.Version{Path: "example.com/deprecated", Version: "v1.0.0"}])
+	assert.Equal(t, &vendorMeta{
+		Explicit:    true,
+		GoVersion:   "1.20",
+		Replacement: module.Version{Path: "example.com/fork", Version: "v1.0.0"},
+	}, vendor.Meta[module.Version{Path: "example.com/replaced", Version: "v1.0.0"}])
+
+	assert.Equal(t, map[string]module.Version{"example.com/other": {Path: "../other"}}, vendor.Replaced)
+}
+
+func TestAnalyzeFile_vendor(t *testing.T) {
+	setupModCache(t)
+
+	file := parseTestdata(t, "vendor_check/go.mod")
+
+	results := AnalyzeFile(file, Options{ReplaceAllowAll: true, CheckVendor: true})
+
+	var reasons []string
+	for _, result := range results {
+		reasons = append(reasons, result.String())
+	}
+
+	expected := []string{
+		"testdata/vendor_check/go.mod:7:2: example.com/newgo@v1.0.0 is marked with go version '1.23' in vendor/modules.txt, but its go.mod file has go version '1.24.1'",
+		"testdata/vendor_check/go.mod:8:2: example.com/deprecated@v1.0.0 is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt",
+		"testdata/vendor_check/go.mod:9:2: example.com/retracted is required at version v1.5.0 in go.mod, but vendor/modules.txt has version v1.4.0",
+		"testdata/vendor_check/go.mod:1:1: example.com/extra@v0.1.0 is marked as explicit in vendor/modules.txt, but not explicitly required in go.mod",
+		"testdata/vendor_check/go.mod:16:2: example.com/replaced is replaced by example.com/fork@v1.1.0 in go.mod, but marked as replaced by example.com/fork@v1.0.0 in vendor/modules.txt",
+		"testdata/vendor_check/go.mod:17:2: example.com/wild is replaced in go.mod, but not marked as replaced in vendor/modules.txt",
+		"testdata/vendor_check/go.mod:1:1: example.com/other is marked as replaced in vendor/modules.txt, but not replaced in go.mod",
+	}
+
+	assert.Equal(t, expected, reasons)
+}
+
+func TestAnalyzeFile_vendor_noVendor(t *testing.T) {
+	file := parseTestdata(t, "replace/go.mod")
+
+	results := AnalyzeFile(file, Options{ReplaceAllowAll: true, CheckVendor: true})
+
+	assert.Empty(t, results)
+}